@@ -0,0 +1,339 @@
+// Command autobattle-sim ejecuta partidas IA vs IA en proceso (sin HTTP ni WebSocket)
+// para balancear estadísticas de unidades.
+//
+// Uso:
+//
+//	go run ./cmd/autobattle-sim -games 200 -seed 1 -format json
+//	go run ./cmd/autobattle-sim -games 50 -config blitz.json -format csv > results.csv
+//	go run ./cmd/autobattle-sim -games 50 -preset blitz -config overrides.json
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"autobattle-server/game"
+)
+
+// Asientos de la partida: el jugador 1 ocupa el lugar del humano y el 2 el de la IA
+const (
+	seatPlayer1 = "player1"
+	seatPlayer2 = "player2"
+	seatDraw    = "draw"
+)
+
+// GameResult resume una partida simulada
+type GameResult struct {
+	Seed   int64                                `json:"seed"`
+	Winner string                               `json:"winner"` // player1, player2, teamN o draw
+	Reason string                               `json:"reason"`
+	Ticks  int                                  `json:"ticks"`
+	Turns  int                                  `json:"turns"`
+	Units  map[string]map[string]*UnitTypeTotal `json:"units"` // seat -> unitType -> totales
+}
+
+// UnitTypeTotal acumula producción y daño de un tipo de unidad
+type UnitTypeTotal struct {
+	Produced      int     `json:"produced"`
+	DamageDealt   int     `json:"damageDealt"`
+	DamagePerUnit float64 `json:"damagePerUnit"`
+}
+
+// Summary agrega los resultados de todas las partidas
+type Summary struct {
+	Games       int                       `json:"games"`
+	WinRates    map[string]float64        `json:"winRates"` // player1, player2, teamN, draw
+	Reasons     map[string]int            `json:"reasons"`
+	AvgTicks    float64                   `json:"avgTicks"`
+	MinTicks    int                       `json:"minTicks"`
+	MaxTicks    int                       `json:"maxTicks"`
+	AvgTurns    float64                   `json:"avgTurns"`
+	UnitTypes   map[string]*UnitTypeTotal `json:"unitTypes"`
+	PhaseConfig game.PhaseConfig          `json:"phaseConfig"`
+}
+
+type report struct {
+	Summary Summary       `json:"summary"`
+	Games   []*GameResult `json:"games,omitempty"`
+}
+
+func main() {
+	games := flag.Int("games", 100, "cantidad de partidas (una por seed)")
+	firstSeed := flag.Int64("seed", 1, "seed de la primera partida; las siguientes usan seed+1, seed+2...")
+	configPath := flag.String("config", "", "archivo JSON con un PhaseConfig (los campos omitidos usan los valores del preset)")
+	preset := flag.String("preset", game.PresetStandard, "preset base: "+strings.Join(game.PresetNames(), ", "))
+	format := flag.String("format", "json", "formato de salida: json o csv")
+	maxTurns := flag.Int("max-turns", 50, "turnos máximos antes de declarar empate")
+	maxTicks := flag.Int("max-ticks", 50000, "ticks máximos antes de declarar empate")
+	workers := flag.Int("workers", runtime.NumCPU(), "partidas simuladas en paralelo")
+	perGame := flag.Bool("per-game", false, "incluir el detalle de cada partida (solo json)")
+	verbose := flag.Bool("v", false, "mostrar logs de la simulación")
+	flag.Parse()
+
+	logLevel := slog.LevelError
+	if *verbose {
+		logLevel = slog.LevelInfo
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})))
+
+	var raw []byte
+	if *configPath != "" {
+		var err error
+		raw, err = os.ReadFile(*configPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "no se pudo leer la configuración:", err)
+			os.Exit(1)
+		}
+	}
+	config, err := game.BuildConfig(*preset, raw)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "configuración inválida:", err)
+		os.Exit(1)
+	}
+
+	if *format != "json" && *format != "csv" {
+		fmt.Fprintln(os.Stderr, "formato desconocido:", *format)
+		os.Exit(2)
+	}
+	if *workers < 1 {
+		*workers = 1
+	}
+
+	results := runAll(*games, *firstSeed, config, *maxTurns, *maxTicks, *workers)
+	summary := summarize(results, config)
+
+	switch *format {
+	case "json":
+		out := report{Summary: summary}
+		if *perGame {
+			out.Games = results
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(out)
+	case "csv":
+		err = writeCSV(os.Stdout, summary)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error escribiendo resultados:", err)
+		os.Exit(1)
+	}
+}
+
+// runAll simula las partidas repartiéndolas entre varios workers
+func runAll(games int, firstSeed int64, config game.PhaseConfig, maxTurns, maxTicks, workers int) []*GameResult {
+	results := make([]*GameResult, games)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = runGame(i+1, firstSeed+int64(i), config, maxTurns, maxTicks)
+			}
+		}()
+	}
+	for i := 0; i < games; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// runGame juega una partida IA vs IA hasta que se cumple una condición de victoria o un límite
+func runGame(id int, seed int64, config game.PhaseConfig, maxTurns, maxTicks int) *GameResult {
+	g := game.NewGameWithSeed(id, seed, config)
+	player1 := g.State.AddPlayer() // Crea también el jugador 2 (IA)
+	g.State.SetPlayerAI(player1.ID, true)
+
+	result := &GameResult{Seed: seed, Winner: seatDraw}
+	for {
+		g.Simulation.ProcessTick()
+
+		if end := g.Simulation.CheckGameEnd(); end != nil {
+			result.Reason = end.Reason
+			switch {
+			case end.Draw:
+				result.Winner = seatDraw
+			case end.WinnerTeam > 0:
+				result.Winner = teamSeat(end.WinnerTeam)
+			default:
+				result.Winner = seatOf(g, end.WinnerID)
+			}
+			break
+		}
+		if g.State.TurnNumber > maxTurns {
+			result.Reason = "max_turns"
+			break
+		}
+		if g.State.Tick >= maxTicks {
+			result.Reason = "max_ticks"
+			break
+		}
+	}
+
+	result.Ticks = g.State.Tick
+	result.Turns = g.State.TurnNumber
+	result.Units = make(map[string]map[string]*UnitTypeTotal)
+	for playerID, p := range g.State.GetMatchStats().Players {
+		seat := seatOf(g, playerID)
+		units, ok := result.Units[seat]
+		if !ok {
+			units = make(map[string]*UnitTypeTotal, len(p.Units))
+			result.Units[seat] = units
+		}
+		for unitType, u := range p.Units {
+			total, ok := units[unitType]
+			if !ok {
+				total = &UnitTypeTotal{}
+				units[unitType] = total
+			}
+			total.Produced += u.Produced
+			total.DamageDealt += u.DamageDealt
+		}
+	}
+	return result
+}
+
+// seatOf retorna el asiento de un jugador: su equipo en partidas por equipos, si no player1
+// (el lugar del humano) o player2 (el de la IA)
+func seatOf(g *game.Game, playerID int) string {
+	if p, ok := g.State.Players[playerID]; ok && p.Team > 0 {
+		return teamSeat(p.Team)
+	}
+	if playerID == g.State.AIPlayerID {
+		return seatPlayer2
+	}
+	return seatPlayer1
+}
+
+func teamSeat(team int) string {
+	return "team" + strconv.Itoa(team)
+}
+
+func summarize(results []*GameResult, config game.PhaseConfig) Summary {
+	summary := Summary{
+		Games:       len(results),
+		WinRates:    map[string]float64{seatPlayer1: 0, seatPlayer2: 0, seatDraw: 0},
+		Reasons:     make(map[string]int),
+		UnitTypes:   make(map[string]*UnitTypeTotal),
+		PhaseConfig: config,
+	}
+	if len(results) == 0 {
+		return summary
+	}
+
+	totalTicks, totalTurns := 0, 0
+	summary.MinTicks = results[0].Ticks
+	for _, r := range results {
+		summary.WinRates[r.Winner]++
+		summary.Reasons[r.Reason]++
+		totalTicks += r.Ticks
+		totalTurns += r.Turns
+		if r.Ticks < summary.MinTicks {
+			summary.MinTicks = r.Ticks
+		}
+		if r.Ticks > summary.MaxTicks {
+			summary.MaxTicks = r.Ticks
+		}
+		for _, units := range r.Units {
+			for unitType, u := range units {
+				total, ok := summary.UnitTypes[unitType]
+				if !ok {
+					total = &UnitTypeTotal{}
+					summary.UnitTypes[unitType] = total
+				}
+				total.Produced += u.Produced
+				total.DamageDealt += u.DamageDealt
+			}
+		}
+	}
+
+	n := float64(len(results))
+	for seat := range summary.WinRates {
+		summary.WinRates[seat] /= n
+	}
+	summary.AvgTicks = float64(totalTicks) / n
+	summary.AvgTurns = float64(totalTurns) / n
+	for _, total := range summary.UnitTypes {
+		if total.Produced > 0 {
+			total.DamagePerUnit = float64(total.DamageDealt) / float64(total.Produced)
+		}
+	}
+	return summary
+}
+
+// writeCSV escribe el resumen en formato largo (section,key,value) para pivotear en hojas de cálculo
+func writeCSV(w io.Writer, summary Summary) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{
+		{"section", "key", "value"},
+		{"summary", "games", strconv.Itoa(summary.Games)},
+		{"summary", "win_rate_" + seatPlayer1, formatFloat(summary.WinRates[seatPlayer1])},
+		{"summary", "win_rate_" + seatPlayer2, formatFloat(summary.WinRates[seatPlayer2])},
+		{"summary", "draw_rate", formatFloat(summary.WinRates[seatDraw])},
+	}
+	seats := make([]string, 0, len(summary.WinRates))
+	for seat := range summary.WinRates {
+		if seat != seatPlayer1 && seat != seatPlayer2 && seat != seatDraw {
+			seats = append(seats, seat)
+		}
+	}
+	sort.Strings(seats)
+	for _, seat := range seats {
+		rows = append(rows, []string{"summary", "win_rate_" + seat, formatFloat(summary.WinRates[seat])})
+	}
+	rows = append(rows,
+		[]string{"summary", "avg_ticks", formatFloat(summary.AvgTicks)},
+		[]string{"summary", "min_ticks", strconv.Itoa(summary.MinTicks)},
+		[]string{"summary", "max_ticks", strconv.Itoa(summary.MaxTicks)},
+		[]string{"summary", "avg_turns", formatFloat(summary.AvgTurns)},
+	)
+
+	reasons := make([]string, 0, len(summary.Reasons))
+	for reason := range summary.Reasons {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		rows = append(rows, []string{"reason", reason, strconv.Itoa(summary.Reasons[reason])})
+	}
+
+	unitTypes := make([]string, 0, len(summary.UnitTypes))
+	for unitType := range summary.UnitTypes {
+		unitTypes = append(unitTypes, unitType)
+	}
+	sort.Strings(unitTypes)
+	for _, unitType := range unitTypes {
+		total := summary.UnitTypes[unitType]
+		section := "unit:" + unitType
+		rows = append(rows,
+			[]string{section, "produced", strconv.Itoa(total.Produced)},
+			[]string{section, "damage_dealt", strconv.Itoa(total.DamageDealt)},
+			[]string{section, "damage_per_unit", formatFloat(total.DamagePerUnit)},
+		)
+	}
+
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
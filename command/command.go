@@ -3,14 +3,23 @@ package command
 type CommandType string
 
 const (
-	CommandDummy      CommandType = "dummy"
-	CommandPlaceBase  CommandType = "place_base" // Colocar base principal
-	CommandSpawnUnit  CommandType = "spawn_unit"
-	CommandUpgrade    CommandType = "upgrade"
-	CommandMoveUnit   CommandType = "move_unit"
-	CommandEndTurn    CommandType = "end_turn"    // Deprecated - usar ready
-	CommandReady      CommandType = "ready"       // Jugador listo para pasar de fase
-	CommandConfirmEnd CommandType = "confirm_end" // Confirmar fin de juego
+	CommandDummy       CommandType = "dummy"
+	CommandPlaceBase   CommandType = "place_base" // Colocar base principal
+	CommandSpawnUnit   CommandType = "spawn_unit"
+	CommandUpgrade     CommandType = "upgrade"
+	CommandMoveUnit    CommandType = "move_unit"
+	CommandFocusTarget CommandType = "focus_target" // Fijar objetivo prioritario de una unidad
+	CommandMoveGroup   CommandType = "move_group"   // Mover varias unidades en formación
+	CommandSetStance   CommandType = "set_stance"   // Cambiar postura de unidades
+	CommandPlayCard    CommandType = "play_card"    // Jugar una carta de hechizo
+	CommandMulligan    CommandType = "mulligan"     // Cambiar cartas de la mano inicial (base_selection)
+	CommandDiscard     CommandType = "discard"      // Descartar cartas de la mano
+	CommandEndTurn     CommandType = "end_turn"     // Deprecated - usar ready
+	CommandReady       CommandType = "ready"        // Jugador listo para pasar de fase
+	CommandConfirmEnd  CommandType = "confirm_end"  // Confirmar fin de juego
+	CommandPause       CommandType = "pause"        // Pausar (o aceptar la pausa pedida por el rival)
+	CommandResume      CommandType = "resume"       // Reanudar (o cancelar la pausa pedida)
+	CommandSurrender   CommandType = "surrender"    // Rendirse (fin de juego pendiente con motivo surrender)
 )
 
 type Command struct {
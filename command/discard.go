@@ -0,0 +1,6 @@
+package command
+
+// DiscardData descarta cartas de la mano (no vuelven al mazo)
+type DiscardData struct {
+	Cards []string `json:"cards"`
+}
@@ -0,0 +1,7 @@
+package command
+
+// FocusTargetData fija el objetivo prioritario de una unidad o estructura (TargetID 0 = limpiar)
+type FocusTargetData struct {
+	UnitID   int `json:"unitId"`
+	TargetID int `json:"targetId"`
+}
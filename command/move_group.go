@@ -0,0 +1,8 @@
+package command
+
+// MoveGroupData mueve varias unidades en formación: el centro del grupo va a (X, Y)
+type MoveGroupData struct {
+	UnitIDs []int `json:"unitIds"`
+	X       int   `json:"x"`
+	Y       int   `json:"y"`
+}
@@ -0,0 +1,6 @@
+package command
+
+// MulliganData devuelve cartas de la mano inicial al mazo y roba la misma cantidad (solo base_selection)
+type MulliganData struct {
+	Cards []string `json:"cards"`
+}
@@ -0,0 +1,10 @@
+package command
+
+// PlayCardData juega una carta de hechizo. Según el hechizo se usa el tile (X, Y),
+// la unidad (UnitID) o ningún objetivo.
+type PlayCardData struct {
+	Card   string `json:"card"`
+	X      int    `json:"x,omitempty"`
+	Y      int    `json:"y,omitempty"`
+	UnitID int    `json:"unitId,omitempty"`
+}
@@ -0,0 +1,11 @@
+package command
+
+// SetStanceData cambia la postura de varias unidades.
+// X/Y (opcionales) fijan el punto de guardia de la postura defensive; Leash su radio.
+type SetStanceData struct {
+	UnitIDs []int  `json:"unitIds"`
+	Stance  string `json:"stance"` // aggressive, defensive, hold
+	X       *int   `json:"x,omitempty"`
+	Y       *int   `json:"y,omitempty"`
+	Leash   int    `json:"leash,omitempty"`
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"autobattle-server/game"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresDeckStore guarda los mazos en la tabla decks (ver migrations/002_decks.sql)
+type PostgresDeckStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresDeckStore(pool *pgxpool.Pool) *PostgresDeckStore {
+	return &PostgresDeckStore{pool: pool}
+}
+
+// NewDeckStore elige el almacenamiento de mazos según DECK_STORE ("memory" o "postgres")
+func NewDeckStore() (game.DeckStore, error) {
+	switch kind := getEnv("DECK_STORE", "memory"); kind {
+	case "memory":
+		return game.NewMemoryDeckStore(), nil
+	case "postgres":
+		if DB == nil {
+			return nil, errors.New("postgres deck store requires a database connection")
+		}
+		return NewPostgresDeckStore(DB), nil
+	default:
+		return nil, fmt.Errorf("unknown DECK_STORE %q", kind)
+	}
+}
+
+func (p *PostgresDeckStore) SaveDeck(owner, name string, cards []string) (game.Deck, error) {
+	raw, err := json.Marshal(cards)
+	if err != nil {
+		return game.Deck{}, err
+	}
+	deck := game.Deck{Owner: owner, Name: name, Cards: append([]string{}, cards...)}
+	err = p.pool.QueryRow(context.Background(), `
+		INSERT INTO decks (owner, name, cards, updated_at) VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (owner, name) DO UPDATE SET cards = EXCLUDED.cards, updated_at = EXCLUDED.updated_at
+		RETURNING id, updated_at`, owner, name, raw).Scan(&deck.ID, &deck.UpdatedAt)
+	if err != nil {
+		return game.Deck{}, fmt.Errorf("failed to save deck: %w", err)
+	}
+	return deck, nil
+}
+
+func (p *PostgresDeckStore) ListDecks(owner string) ([]game.Deck, error) {
+	rows, err := p.pool.Query(context.Background(),
+		`SELECT id, name, cards, updated_at FROM decks WHERE owner = $1 ORDER BY id`, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list decks: %w", err)
+	}
+	defer rows.Close()
+
+	decks := []game.Deck{}
+	for rows.Next() {
+		deck, err := scanDeck(rows, owner)
+		if err != nil {
+			return nil, err
+		}
+		decks = append(decks, deck)
+	}
+	return decks, rows.Err()
+}
+
+func (p *PostgresDeckStore) GetDeck(owner string, id int) (game.Deck, error) {
+	row := p.pool.QueryRow(context.Background(),
+		`SELECT id, name, cards, updated_at FROM decks WHERE owner = $1 AND id = $2`, owner, id)
+	deck, err := scanDeck(row, owner)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return game.Deck{}, game.ErrDeckNotFound
+	}
+	return deck, err
+}
+
+func (p *PostgresDeckStore) DeleteDeck(owner string, id int) error {
+	tag, err := p.pool.Exec(context.Background(), `DELETE FROM decks WHERE owner = $1 AND id = $2`, owner, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete deck: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return game.ErrDeckNotFound
+	}
+	return nil
+}
+
+func scanDeck(row pgx.Row, owner string) (game.Deck, error) {
+	var (
+		deck      = game.Deck{Owner: owner}
+		raw       []byte
+		updatedAt time.Time
+	)
+	if err := row.Scan(&deck.ID, &deck.Name, &raw, &updatedAt); err != nil {
+		return game.Deck{}, err
+	}
+	if err := json.Unmarshal(raw, &deck.Cards); err != nil {
+		return game.Deck{}, fmt.Errorf("invalid cards for deck %d: %w", deck.ID, err)
+	}
+	deck.UpdatedAt = updatedAt
+	return deck, nil
+}
@@ -0,0 +1,247 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConfigFieldError describe un campo inválido de la configuración (nombre JSON del campo)
+type ConfigFieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ConfigValidationError lista todos los campos inválidos de una configuración
+type ConfigValidationError struct {
+	Fields []ConfigFieldError `json:"fields"`
+}
+
+func (e *ConfigValidationError) Error() string {
+	problems := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		problems[i] = f.Field + ": " + f.Message
+	}
+	return "invalid config: " + strings.Join(problems, "; ")
+}
+
+// add registra un campo inválido
+func (e *ConfigValidationError) add(field, format string, args ...any) {
+	e.Fields = append(e.Fields, ConfigFieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// min registra el campo si value < limit
+func (e *ConfigValidationError) min(field string, value, limit int) {
+	if value < limit {
+		e.add(field, "must be >= %d, got %d", limit, value)
+	}
+}
+
+// minFloat registra el campo si value < limit
+func (e *ConfigValidationError) minFloat(field string, value, limit float64) {
+	if value < limit {
+		e.add(field, "must be >= %g, got %g", limit, value)
+	}
+}
+
+// oneOf registra el campo si value no es ninguna de las opciones ("" toma el valor por defecto)
+func (e *ConfigValidationError) oneOf(field, value string, options ...string) {
+	if value == "" {
+		return
+	}
+	for _, option := range options {
+		if value == option {
+			return
+		}
+	}
+	e.add(field, "must be one of %s, got %q", strings.Join(options, ", "), value)
+}
+
+// waveUnitTypes son los tipos de unidad que pueden formar parte de una oleada
+var waveUnitTypes = []string{TypeLandSoldier, TypeWarrior, TypeSiegeRam, TypeMedic, TypeEngineer}
+
+// Validate verifica rangos y valores permitidos de cada campo. Retorna un
+// *ConfigValidationError con todos los campos inválidos (no solo el primero) o nil si es válida.
+func (c PhaseConfig) Validate() error {
+	e := &ConfigValidationError{}
+
+	// Fases y cartas
+	e.min("turnStartDuration", c.TurnStartDuration, 1)
+	e.min("preparationDuration", c.PreparationDuration, 1)
+	e.min("battleDuration", c.BattleDuration, 1)
+	e.min("turnEndDuration", c.TurnEndDuration, 1)
+	e.min("aiReadyDelay", c.AIReadyDelay, 0)
+	e.min("disconnectTimeoutSeconds", c.DisconnectTimeoutSeconds, 1)
+	e.min("cardsPerTurn", c.CardsPerTurn, 0)
+	e.min("initialCardsPerHand", c.InitialCardsPerHand, 0)
+
+	// Combate
+	for _, damageType := range sortedKeys(c.DamageMatrix) {
+		for armor, multiplier := range c.DamageMatrix[damageType] {
+			e.minFloat(fmt.Sprintf("damageMatrix.%s.%s", damageType, armor), multiplier, 0)
+		}
+	}
+	e.min("structureRepairPercent", c.StructureRepairPercent, 0)
+	if c.StructureRepairPercent > 100 {
+		e.add("structureRepairPercent", "must be <= 100, got %d", c.StructureRepairPercent)
+	}
+	e.minFloat("siegeWallMultiplier", c.SiegeWallMultiplier, 0)
+
+	// Mano, mazo y tiempos
+	e.min("maxHandSize", c.MaxHandSize, 0)
+	if c.MaxHandSize > 0 && c.InitialCardsPerHand > c.MaxHandSize {
+		e.add("initialCardsPerHand", "must be <= maxHandSize (%d), got %d", c.MaxHandSize, c.InitialCardsPerHand)
+	}
+	e.oneOf("handOverflow", string(c.HandOverflow), string(HandOverflowBurn), string(HandOverflowDiscard))
+	e.min("maxMulligans", c.MaxMulligans, 0)
+	e.min("fatigueDamage", c.FatigueDamage, 0)
+	e.min("timeBankMs", c.TimeBankMs, 0)
+	e.min("timeBankIncrementMs", c.TimeBankIncrementMs, 0)
+	e.min("timeBankMaxMs", c.TimeBankMaxMs, 0)
+	e.min("maxPauses", c.MaxPauses, 0)
+	if c.MaxPauses > 0 {
+		// Con 0 segundos cada pausa se reanudaría sola en el tick siguiente
+		e.min("maxPauseSeconds", c.MaxPauseSeconds, 1)
+	} else {
+		e.min("maxPauseSeconds", c.MaxPauseSeconds, 0)
+	}
+
+	// Límite de turnos y muerte súbita
+	e.min("maxTurns", c.MaxTurns, 0)
+	e.oneOf("tiebreak", string(c.Tiebreak), string(TiebreakBaseHP), string(TiebreakStructureValue), string(TiebreakScore), string(TiebreakNone))
+	e.min("suddenDeathTurn", c.SuddenDeathTurn, 0)
+	e.oneOf("suddenDeathMode", string(c.SuddenDeathMode), string(SuddenDeathAttackBuff), string(SuddenDeathBaseDamage))
+	e.minFloat("suddenDeathDamageMultiplier", c.SuddenDeathDamageMultiplier, 0)
+	e.min("suddenDeathBaseDamage", c.SuddenDeathBaseDamage, 0)
+
+	// Veteranía
+	for i, rank := range c.Veterancy {
+		field := fmt.Sprintf("veterancy[%d]", i)
+		e.min(field+".kills", rank.Kills, 0)
+		e.min(field+".damageDealt", rank.DamageDealt, 0)
+		if rank.Kills <= 0 && rank.DamageDealt <= 0 {
+			e.add(field, "needs kills or damageDealt > 0")
+		}
+		e.minFloat(field+".hpBonus", rank.HPBonus, 0)
+		e.minFloat(field+".damageBonus", rank.DamageBonus, 0)
+		e.minFloat(field+".attackSpeedBonus", rank.AttackSpeedBonus, 0)
+		if rank.AttackSpeedBonus >= 1 {
+			e.add(field+".attackSpeedBonus", "must be < 1, got %g", rank.AttackSpeedBonus)
+		}
+	}
+
+	// Modo oleadas
+	e.oneOf("mode", string(c.Mode), string(GameModeVersus), string(GameModeWaves))
+	for i, wave := range c.Waves {
+		field := fmt.Sprintf("waves[%d]", i)
+		if len(wave.Groups) == 0 {
+			e.add(field+".groups", "must not be empty")
+		}
+		e.min(field+".spawnInterval", wave.SpawnInterval, 0)
+		for j, group := range wave.Groups {
+			groupField := fmt.Sprintf("%s.groups[%d]", field, j)
+			e.oneOf(groupField+".unitType", group.UnitType, waveUnitTypes...)
+			if group.UnitType == "" {
+				e.add(groupField+".unitType", "must not be empty")
+			}
+			e.min(groupField+".count", group.Count, 1)
+		}
+	}
+	for i, point := range c.WaveSpawnPoints {
+		e.min(fmt.Sprintf("waveSpawnPoints[%d].x", i), point.X, 0)
+		e.min(fmt.Sprintf("waveSpawnPoints[%d].y", i), point.Y, 0)
+	}
+
+	// Puntos de control
+	e.min("controlPointRadius", c.ControlPointRadius, 0)
+	e.min("controlPointCaptureTicks", c.ControlPointCaptureTicks, 1)
+	e.min("controlPointBuildRange", c.ControlPointBuildRange, 0)
+	e.min("controlPointCardDraws", c.ControlPointCardDraws, 0)
+
+	// Equipos
+	if c.teamsEnabled() {
+		teams := make(map[int]bool)
+		humans := 0
+		for i, seat := range c.Seats {
+			e.min(fmt.Sprintf("seats[%d].team", i), seat.Team, 1)
+			teams[seat.Team] = true
+			if !seat.AI {
+				humans++
+			}
+		}
+		if len(teams) < 2 {
+			e.add("seats", "needs at least two teams")
+		}
+		if humans == 0 {
+			e.add("seats", "needs at least one human seat")
+		}
+		if c.Mode == GameModeWaves {
+			e.add("seats", "team seats cannot be combined with waves mode")
+		}
+	}
+
+	if len(e.Fields) > 0 {
+		return e
+	}
+	return nil
+}
+
+// sortedKeys retorna las claves de la matriz de daño ordenadas (errores en orden estable)
+func sortedKeys(m DamageMatrix) []DamageType {
+	keys := make([]DamageType, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// MergeConfig aplica una configuración parcial en JSON sobre base: los campos ausentes conservan
+// el valor de base, los listas presentes (veterancy, waves, seats...) se reemplazan completas y
+// damageMatrix reemplaza solo las filas enviadas. Los campos desconocidos son un error.
+func MergeConfig(base PhaseConfig, partial json.RawMessage) (PhaseConfig, error) {
+	if len(bytes.TrimSpace(partial)) == 0 || bytes.Equal(bytes.TrimSpace(partial), []byte("null")) {
+		return base, nil
+	}
+	merged := base
+	if base.DamageMatrix != nil {
+		// Copiar la matriz para no modificar la de base al fusionar filas
+		merged.DamageMatrix = make(DamageMatrix, len(base.DamageMatrix))
+		for damageType, row := range base.DamageMatrix {
+			merged.DamageMatrix[damageType] = row
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(partial))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&merged); err != nil {
+		return base, err
+	}
+	return merged, nil
+}
+
+// BuildConfig arma la configuración de una partida nueva: parte del preset indicado ("" =
+// standard), aplica encima la configuración parcial y valida el resultado. Un preset
+// desconocido o un JSON inválido se reportan como *ConfigValidationError.
+func BuildConfig(preset string, partial json.RawMessage) (PhaseConfig, error) {
+	if preset == "" {
+		preset = PresetStandard
+	}
+	base, ok := PresetConfig(preset)
+	if !ok {
+		e := &ConfigValidationError{}
+		e.add("preset", "unknown preset %q (available: %s)", preset, strings.Join(PresetNames(), ", "))
+		return PhaseConfig{}, e
+	}
+	config, err := MergeConfig(base, partial)
+	if err != nil {
+		e := &ConfigValidationError{}
+		e.add("config", "%v", err)
+		return PhaseConfig{}, e
+	}
+	if err := config.Validate(); err != nil {
+		return PhaseConfig{}, err
+	}
+	return config, nil
+}
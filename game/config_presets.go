@@ -0,0 +1,90 @@
+package game
+
+// Nombres de los presets de configuración
+const (
+	PresetBlitz    = "blitz"
+	PresetStandard = "standard"
+	PresetMarathon = "marathon"
+	PresetSandbox  = "sandbox"
+)
+
+// ConfigPreset es una configuración con nombre que se puede usar como base al crear una partida
+type ConfigPreset struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Config      PhaseConfig `json:"config"`
+}
+
+// ConfigPresets retorna los presets disponibles, en orden de duración de la partida
+func ConfigPresets() []ConfigPreset {
+	return []ConfigPreset{
+		{Name: PresetBlitz, Description: "Partidas cortas: fases rápidas, más cartas y muerte súbita desde el turno 6", Config: blitzConfig()},
+		{Name: PresetStandard, Description: "Configuración por defecto", Config: DefaultPhaseConfig()},
+		{Name: PresetMarathon, Description: "Partidas largas: fases lentas, reparación entre turnos y sin límite de turnos", Config: marathonConfig()},
+		{Name: PresetSandbox, Description: "Pruebas libres: mano sin límite, muchas cartas y preparación larga", Config: sandboxConfig()},
+	}
+}
+
+// PresetNames retorna los nombres de los presets disponibles
+func PresetNames() []string {
+	presets := ConfigPresets()
+	names := make([]string, len(presets))
+	for i, preset := range presets {
+		names[i] = preset.Name
+	}
+	return names
+}
+
+// PresetConfig retorna la configuración de un preset por nombre
+func PresetConfig(name string) (PhaseConfig, bool) {
+	for _, preset := range ConfigPresets() {
+		if preset.Name == name {
+			return preset.Config, true
+		}
+	}
+	return PhaseConfig{}, false
+}
+
+// blitzConfig acorta cada fase y fuerza el final con límite de turnos y muerte súbita
+func blitzConfig() PhaseConfig {
+	config := DefaultPhaseConfig()
+	config.TurnStartDuration = 5         // ~1 segundo
+	config.PreparationDuration = 75      // ~15 segundos
+	config.BattleDuration = 150          // ~30 segundos
+	config.TurnEndDuration = 5           // ~1 segundo
+	config.CardsPerTurn = 2              // 2 cartas por turno
+	config.InitialCardsPerHand = 5       // 5 cartas iniciales
+	config.MaxPauses = 1                 // Una sola pausa
+	config.MaxPauseSeconds = 30          // De hasta 30 segundos
+	config.MaxTurns = 10                 // Desempate por HP de base al terminar el turno 10
+	config.SuddenDeathTurn = 6           // Muerte súbita desde el turno 6
+	config.ControlPointCaptureTicks = 15 // ~3 segundos
+	return config
+}
+
+// marathonConfig alarga las fases y repara estructuras entre turnos
+func marathonConfig() PhaseConfig {
+	config := DefaultPhaseConfig()
+	config.PreparationDuration = 300     // ~60 segundos
+	config.BattleDuration = 600          // ~2 minutos
+	config.StructureRepairPercent = 10   // 10% de MaxHP por turno
+	config.MaxHandSize = 12              // Mano más grande
+	config.MaxMulligans = 2              // Un mulligan extra
+	config.MaxPauses = 5                 // Más pausas
+	config.ControlPointCaptureTicks = 50 // ~10 segundos
+	return config
+}
+
+// sandboxConfig prioriza probar cartas: mano sin límite, muchas cartas y preparación larga
+func sandboxConfig() PhaseConfig {
+	config := DefaultPhaseConfig()
+	config.PreparationDuration = 1500 // ~5 minutos
+	config.AIReadyDelay = 1500        // La IA espera toda la preparación
+	config.CardsPerTurn = 5           // 5 cartas por turno
+	config.InitialCardsPerHand = 10   // 10 cartas iniciales
+	config.MaxHandSize = 0            // Sin límite de mano
+	config.MaxPauses = 100            // Pausas prácticamente ilimitadas
+	config.MaxPauseSeconds = 600      // De hasta 10 minutos
+	config.DisconnectTimeoutSeconds = 300
+	return config
+}
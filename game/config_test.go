@@ -0,0 +1,84 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestBuildConfig(t *testing.T) {
+	t.Run("partial config keeps the preset values", func(t *testing.T) {
+		config, err := BuildConfig(PresetBlitz, json.RawMessage(`{"battleDuration": 90, "damageMatrix": {"siege": {"fortified": 3}}}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		blitz, _ := PresetConfig(PresetBlitz)
+		if config.BattleDuration != 90 || config.CardsPerTurn != blitz.CardsPerTurn || config.MaxTurns != blitz.MaxTurns {
+			t.Errorf("expected only battleDuration to change, got %+v", config)
+		}
+		if config.DamageMatrix.Multiplier(DamageSiege, ArmorFortified) != 3 || config.DamageMatrix.Multiplier(DamagePiercing, ArmorFortified) != blitz.DamageMatrix.Multiplier(DamagePiercing, ArmorFortified) {
+			t.Errorf("expected the damage matrix to merge the siege row, got %v", config.DamageMatrix)
+		}
+		if blitz.DamageMatrix.Multiplier(DamageSiege, ArmorFortified) == 3 {
+			t.Errorf("merging must not modify the preset")
+		}
+	})
+
+	t.Run("invalid fields are all reported", func(t *testing.T) {
+		_, err := BuildConfig("", json.RawMessage(`{"battleDuration": 0, "cardsPerTurn": -1, "handOverflow": "keep"}`))
+		var invalid *ConfigValidationError
+		if !errors.As(err, &invalid) {
+			t.Fatalf("expected a config validation error, got %v", err)
+		}
+		fields := map[string]bool{}
+		for _, f := range invalid.Fields {
+			fields[f.Field] = true
+		}
+		if len(invalid.Fields) != 3 || !fields["battleDuration"] || !fields["cardsPerTurn"] || !fields["handOverflow"] {
+			t.Errorf("unexpected invalid fields %+v", invalid.Fields)
+		}
+	})
+
+	t.Run("pauses need a positive duration", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			config string
+			valid  bool
+		}{
+			"zero seconds with pauses":    {`{"maxPauses": 2, "maxPauseSeconds": 0}`, false},
+			"zero seconds without pauses": {`{"maxPauses": 0, "maxPauseSeconds": 0}`, true},
+			"one second with pauses":      {`{"maxPauses": 2, "maxPauseSeconds": 1}`, true},
+		} {
+			_, err := BuildConfig("", json.RawMessage(tc.config))
+			var invalid *ConfigValidationError
+			switch {
+			case tc.valid && err != nil:
+				t.Errorf("%s: unexpected error %v", name, err)
+			case !tc.valid && (!errors.As(err, &invalid) || len(invalid.Fields) != 1 || invalid.Fields[0].Field != "maxPauseSeconds"):
+				t.Errorf("%s: expected a single maxPauseSeconds error, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("unknown fields and presets are rejected", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			preset, config, field string
+		}{
+			"unknown field":  {"", `{"batleDuration": 10}`, "config"},
+			"unknown preset": {"speedrun", "", "preset"},
+		} {
+			_, err := BuildConfig(tc.preset, json.RawMessage(tc.config))
+			var invalid *ConfigValidationError
+			if !errors.As(err, &invalid) || invalid.Fields[0].Field != tc.field {
+				t.Errorf("%s: expected an error on %q, got %v", name, tc.field, err)
+			}
+		}
+	})
+
+	t.Run("every preset is valid", func(t *testing.T) {
+		for _, preset := range ConfigPresets() {
+			if err := preset.Config.Validate(); err != nil {
+				t.Errorf("preset %s: %v", preset.Name, err)
+			}
+		}
+	})
+}
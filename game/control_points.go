@@ -0,0 +1,153 @@
+package game
+
+import "log/slog"
+
+// ControlPoint es un punto de control neutral. Lo captura el jugador con más unidades terrestres
+// dentro de Radius durante CaptureTicks ticks de batalla seguidos; el dueño lo conserva hasta
+// que otro lo capture.
+type ControlPoint struct {
+	ID           int `json:"id"`
+	X            int `json:"x"`
+	Y            int `json:"y"`
+	Radius       int `json:"radius"`
+	OwnerID      int `json:"ownerId"`               // 0 = neutral
+	CapturingID  int `json:"capturingId,omitempty"` // Jugador que lo está capturando
+	Progress     int `json:"progress"`              // Ticks acumulados por CapturingID
+	CaptureTicks int `json:"captureTicks"`
+}
+
+// ControlPointCapturedEventData es el payload de un evento "control_point_captured"
+type ControlPointCapturedEventData struct {
+	PointID         int `json:"pointId"`
+	PlayerID        int `json:"playerId"`
+	PreviousOwnerID int `json:"previousOwnerId,omitempty"`
+}
+
+// controlPointsLocked retorna los puntos de control, creándolos desde el mapa la primera vez
+// (requiere lock tomado)
+func (g *GameState) controlPointsLocked() []*ControlPoint {
+	if g.ControlPoints == nil && g.Map != nil {
+		g.ControlPoints = make([]*ControlPoint, 0, len(g.Map.ControlPoints))
+		for i, p := range g.Map.ControlPoints {
+			g.ControlPoints = append(g.ControlPoints, &ControlPoint{
+				ID:           i + 1,
+				X:            p.X,
+				Y:            p.Y,
+				Radius:       g.Config.ControlPointRadius,
+				CaptureTicks: g.Config.ControlPointCaptureTicks,
+			})
+		}
+	}
+	return g.ControlPoints
+}
+
+// controlPointsSnapshotLocked retorna una copia de los puntos de control (requiere lock tomado)
+func (g *GameState) controlPointsSnapshotLocked() []ControlPoint {
+	points := g.controlPointsLocked()
+	if len(points) == 0 {
+		return nil
+	}
+	out := make([]ControlPoint, len(points))
+	for i, p := range points {
+		out[i] = *p
+	}
+	return out
+}
+
+// ownedControlPointsLocked cuenta los puntos de control de un jugador (requiere lock tomado)
+func (g *GameState) ownedControlPointsLocked(playerID int) int {
+	n := 0
+	for _, p := range g.controlPointsLocked() {
+		if p.OwnerID == playerID {
+			n++
+		}
+	}
+	return n
+}
+
+// withinControlPointAreaLocked indica si (x, y) está en el área de construcción de un punto de
+// control del jugador o, con SharedBuildArea, de un aliado (requiere lock tomado)
+func (g *GameState) withinControlPointAreaLocked(playerID, x, y int) bool {
+	if g.Config.ControlPointBuildRange <= 0 {
+		return false
+	}
+	for _, p := range g.controlPointsLocked() {
+		if p.OwnerID != 0 && g.buildsForLocked(p.OwnerID, playerID) && abs(x-p.X)+abs(y-p.Y) <= g.Config.ControlPointBuildRange {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateControlPoints avanza la captura de cada punto según las unidades terrestres a su radio.
+// Sin nadie cerca la captura en curso retrocede; disputado (empate entre equipos) queda congelada.
+func (s *GameSimulation) UpdateControlPoints() {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	g := s.state
+	for _, point := range g.controlPointsLocked() {
+		counts := make(map[int]int)
+		for _, unit := range g.Units {
+			if unit.Category != CategoryLandUnit || unit.HP <= 0 {
+				continue
+			}
+			if abs(unit.X-point.X)+abs(unit.Y-point.Y) <= point.Radius {
+				counts[unit.PlayerID]++
+			}
+		}
+
+		// Los aliados suman juntos; captura el jugador con más unidades del equipo que lidera
+		teamCounts := make(map[int]int)
+		for playerID, n := range counts {
+			teamCounts[g.teamOfLocked(playerID)] += n
+		}
+		leadTeam, best, tied := 0, 0, false
+		for team, n := range teamCounts {
+			switch {
+			case n > best:
+				leadTeam, best, tied = team, n, false
+			case n == best:
+				tied = true
+			}
+		}
+		leader := 0
+		for playerID, n := range counts {
+			if g.teamOfLocked(playerID) != leadTeam {
+				continue
+			}
+			if leader == 0 || n > counts[leader] || (n == counts[leader] && playerID < leader) {
+				leader = playerID
+			}
+		}
+
+		switch {
+		case len(counts) == 0:
+			if point.Progress > 0 {
+				point.Progress--
+			}
+			if point.Progress == 0 {
+				point.CapturingID = 0
+			}
+		case tied:
+			// Disputado: la captura queda congelada
+		case point.OwnerID != 0 && g.alliedLocked(leader, point.OwnerID):
+			point.CapturingID, point.Progress = 0, 0
+		default:
+			if point.CapturingID == 0 || !g.alliedLocked(point.CapturingID, leader) {
+				point.CapturingID, point.Progress = leader, 0
+			}
+			point.Progress++
+			if point.Progress >= point.CaptureTicks {
+				previous := point.OwnerID
+				point.OwnerID, point.CapturingID, point.Progress = leader, 0, 0
+				g.emitEventLocked(EventControlPointCaptured, ControlPointCapturedEventData{
+					PointID:         point.ID,
+					PlayerID:        leader,
+					PreviousOwnerID: previous,
+				})
+				slog.Info("Control point captured", "tick", g.Tick, "pointId", point.ID, "playerId", leader, "previousOwnerId", previous)
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package game
+
+import "math"
+
+// DamageType define el tipo de daño de un atacante
+type DamageType string
+
+const (
+	DamageMelee    DamageType = "melee"    // Cuerpo a cuerpo (soldados, warriors)
+	DamagePiercing DamageType = "piercing" // Proyectiles (torres)
+	DamageSiege    DamageType = "siege"    // Asedio (efectivo contra estructuras)
+	DamageNaval    DamageType = "naval"    // Bombardeo naval
+)
+
+// ArmorClass define la clase de armadura de un objetivo
+type ArmorClass string
+
+const (
+	ArmorLight     ArmorClass = "light"     // Infantería
+	ArmorHeavy     ArmorClass = "heavy"     // Barcos y unidades blindadas
+	ArmorFortified ArmorClass = "fortified" // Estructuras
+)
+
+// SplashFalloff define cómo se atenúa el daño en área según la distancia al impacto
+type SplashFalloff string
+
+const (
+	FalloffNone   SplashFalloff = "none"   // Daño completo en todo el radio
+	FalloffLinear SplashFalloff = "linear" // 1 - d/(radio+1)
+	FalloffHalf   SplashFalloff = "half"   // Se reduce a la mitad por cada tile de distancia
+)
+
+// Factor retorna el multiplicador de daño a distancia dist del impacto para un radio dado
+func (f SplashFalloff) Factor(dist, radius int) float64 {
+	if dist <= 0 {
+		return 1.0
+	}
+	if dist > radius {
+		return 0
+	}
+	switch f {
+	case FalloffLinear:
+		return 1.0 - float64(dist)/float64(radius+1)
+	case FalloffHalf:
+		return math.Pow(0.5, float64(dist))
+	default:
+		return 1.0
+	}
+}
+
+// DamageMatrix define el multiplicador de daño para cada combinación tipo de daño / armadura.
+// Las combinaciones ausentes usan multiplicador 1.0.
+type DamageMatrix map[DamageType]map[ArmorClass]float64
+
+// DefaultDamageMatrix retorna la matriz de multiplicadores por defecto
+func DefaultDamageMatrix() DamageMatrix {
+	return DamageMatrix{
+		DamageMelee: {
+			ArmorLight:     1.0,
+			ArmorHeavy:     0.75,
+			ArmorFortified: 0.75,
+		},
+		DamagePiercing: {
+			ArmorLight:     1.25,
+			ArmorHeavy:     0.75,
+			ArmorFortified: 0.5,
+		},
+		DamageSiege: {
+			ArmorLight:     0.5,
+			ArmorHeavy:     1.0,
+			ArmorFortified: 2.0,
+		},
+		DamageNaval: {
+			ArmorLight:     1.0,
+			ArmorHeavy:     1.25,
+			ArmorFortified: 1.25,
+		},
+	}
+}
+
+// Multiplier retorna el multiplicador para un tipo de daño contra una clase de armadura
+func (m DamageMatrix) Multiplier(damageType DamageType, armor ArmorClass) float64 {
+	if row, ok := m[damageType]; ok {
+		if mult, ok := row[armor]; ok {
+			return mult
+		}
+	}
+	return 1.0
+}
+
+// ApplyDamageMultiplier escala un daño base con redondeo; un golpe con daño y multiplicador
+// positivos siempre hace al menos 1 punto.
+func ApplyDamageMultiplier(damage int, mult float64) int {
+	if damage <= 0 || mult <= 0 {
+		return 0
+	}
+	scaled := int(math.Round(float64(damage) * mult))
+	if scaled < 1 {
+		scaled = 1
+	}
+	return scaled
+}
+
+// damageMatrixLocked retorna la matriz de la partida o la de por defecto si la config no trae una
+// (requiere lock tomado)
+func (g *GameState) damageMatrixLocked() DamageMatrix {
+	if g.Config.DamageMatrix != nil {
+		return g.Config.DamageMatrix
+	}
+	return DefaultDamageMatrix()
+}
+
+// damageAgainstLocked calcula el daño de un ataque de attacker sobre target (requiere lock tomado)
+func (g *GameState) damageAgainstLocked(attacker, target *UnitState) int {
+	return g.splashDamageLocked(attacker, target, 1.0)
+}
+
+// splashDamageLocked calcula el daño sobre target aplicando además un factor de atenuación
+// (requiere lock tomado)
+func (g *GameState) splashDamageLocked(attacker, target *UnitState, falloff float64) int {
+	mult := g.damageMatrixLocked().Multiplier(attacker.DamageType, target.ArmorClass)
+	if attacker.DamageType == DamageSiege && target.UnitType == TypeWall && g.Config.SiegeWallMultiplier > 0 {
+		mult *= g.Config.SiegeWallMultiplier
+	}
+	mult *= g.suddenDeathMultiplierLocked()
+	return ApplyDamageMultiplier(attacker.AttackDamage, mult*falloff*attacker.damageMultiplier())
+}
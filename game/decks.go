@@ -0,0 +1,225 @@
+package game
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DeckRules define las restricciones que debe cumplir un mazo armado por un jugador
+type DeckRules struct {
+	MinSize    int            `json:"minSize"`    // Cantidad mínima de cartas
+	MaxSize    int            `json:"maxSize"`    // Cantidad máxima de cartas
+	MaxCopies  int            `json:"maxCopies"`  // Copias máximas de una misma carta
+	CopyLimits map[string]int `json:"copyLimits"` // Límites específicos por carta (reemplazan MaxCopies)
+	Pool       []string       `json:"pool"`       // Cartas permitidas
+}
+
+// DefaultDeckRules retorna las reglas por defecto. El mazo por defecto (defaultDeck) las cumple.
+func DefaultDeckRules() DeckRules {
+	return DeckRules{
+		MinSize:   40,
+		MaxSize:   150,
+		MaxCopies: 30,
+		CopyLimits: map[string]int{
+			TypeSiegeRam:  5,
+			SpellFireball: 5,
+			SpellRally:    5,
+			SpellHealWave: 5,
+			SpellReveal:   5,
+		},
+		Pool: []string{
+			TypeTower,
+			TypeLandGenerator,
+			TypeNavalGenerator,
+			TypeWall,
+			TypeWarrior,
+			TypeSiegeRam,
+			TypeMedic,
+			TypeEngineer,
+			SpellFireball,
+			SpellRally,
+			SpellHealWave,
+			SpellReveal,
+		},
+	}
+}
+
+// copyLimit retorna las copias máximas permitidas de una carta
+func (r DeckRules) copyLimit(card string) int {
+	if limit, ok := r.CopyLimits[card]; ok {
+		return limit
+	}
+	return r.MaxCopies
+}
+
+// DeckValidationError lista todos los problemas encontrados al validar un mazo
+type DeckValidationError struct {
+	Problems []string `json:"problems"`
+}
+
+func (e *DeckValidationError) Error() string {
+	return "invalid deck: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate verifica tamaño, cartas permitidas y límite de copias. Retorna un
+// *DeckValidationError con todos los problemas (no solo el primero) o nil si el mazo es válido.
+func (r DeckRules) Validate(cards []string) error {
+	var problems []string
+	if len(cards) < r.MinSize {
+		problems = append(problems, fmt.Sprintf("deck has %d cards, minimum is %d", len(cards), r.MinSize))
+	}
+	if len(cards) > r.MaxSize {
+		problems = append(problems, fmt.Sprintf("deck has %d cards, maximum is %d", len(cards), r.MaxSize))
+	}
+
+	allowed := make(map[string]bool, len(r.Pool))
+	for _, card := range r.Pool {
+		allowed[card] = true
+	}
+	counts := make(map[string]int)
+	for _, card := range cards {
+		counts[card]++
+	}
+
+	// Orden estable para que el mensaje no dependa de la iteración del mapa
+	names := make([]string, 0, len(counts))
+	for card := range counts {
+		names = append(names, card)
+	}
+	sort.Strings(names)
+	for _, card := range names {
+		if !allowed[card] {
+			problems = append(problems, fmt.Sprintf("card %q is not allowed", card))
+			continue
+		}
+		if limit := r.copyLimit(card); counts[card] > limit {
+			problems = append(problems, fmt.Sprintf("card %q has %d copies, maximum is %d", card, counts[card], limit))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &DeckValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// Deck es un mazo guardado por un jugador
+type Deck struct {
+	ID        int       `json:"id"`
+	Owner     string    `json:"owner"` // Clave secreta del dueño (ver NewDeckOwnerKey)
+	Name      string    `json:"name"`
+	Cards     []string  `json:"cards"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// deckOwnerKeyBytes es el largo en bytes de una clave de dueño (32 caracteres en hex)
+const deckOwnerKeyBytes = 16
+
+// NewDeckOwnerKey genera una clave de dueño de mazos. Los mazos no están atados a una cuenta:
+// quien conoce la clave puede listarlos, usarlos, reemplazarlos y borrarlos, así que el
+// servidor la emite al azar en lugar de aceptar un nombre elegido por el cliente.
+func NewDeckOwnerKey() (string, error) {
+	key := make([]byte, deckOwnerKeyBytes)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// IsDeckOwnerKey indica si owner tiene la forma de una clave emitida por NewDeckOwnerKey
+func IsDeckOwnerKey(owner string) bool {
+	if len(owner) != 2*deckOwnerKeyBytes {
+		return false
+	}
+	_, err := hex.DecodeString(owner)
+	return err == nil && strings.ToLower(owner) == owner
+}
+
+// ErrDeckNotFound se retorna cuando el mazo no existe o no pertenece al jugador
+var ErrDeckNotFound = errors.New("deck not found")
+
+// DeckStore persiste los mazos de cada jugador. Guardar un mazo con un nombre ya usado por el
+// mismo dueño lo reemplaza (conservando su ID).
+type DeckStore interface {
+	SaveDeck(owner, name string, cards []string) (Deck, error)
+	ListDecks(owner string) ([]Deck, error)
+	GetDeck(owner string, id int) (Deck, error)
+	DeleteDeck(owner string, id int) error
+}
+
+// MemoryDeckStore guarda los mazos en memoria (se pierden al reiniciar el servidor)
+type MemoryDeckStore struct {
+	mu     sync.Mutex
+	decks  map[int]Deck
+	nextID int
+}
+
+func NewMemoryDeckStore() *MemoryDeckStore {
+	return &MemoryDeckStore{
+		decks:  make(map[int]Deck),
+		nextID: 1,
+	}
+}
+
+func (m *MemoryDeckStore) SaveDeck(owner, name string, cards []string) (Deck, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deck := Deck{Owner: owner, Name: name, Cards: append([]string{}, cards...), UpdatedAt: time.Now()}
+	for id, existing := range m.decks {
+		if existing.Owner == owner && existing.Name == name {
+			deck.ID = id
+		}
+	}
+	if deck.ID == 0 {
+		deck.ID = m.nextID
+		m.nextID++
+	}
+	m.decks[deck.ID] = deck
+	return deck, nil
+}
+
+func (m *MemoryDeckStore) ListDecks(owner string) ([]Deck, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	decks := []Deck{}
+	for _, deck := range m.decks {
+		if deck.Owner == owner {
+			deck.Cards = append([]string{}, deck.Cards...)
+			decks = append(decks, deck)
+		}
+	}
+	sort.Slice(decks, func(i, j int) bool { return decks[i].ID < decks[j].ID })
+	return decks, nil
+}
+
+func (m *MemoryDeckStore) GetDeck(owner string, id int) (Deck, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deck, ok := m.decks[id]
+	if !ok || deck.Owner != owner {
+		return Deck{}, ErrDeckNotFound
+	}
+	deck.Cards = append([]string{}, deck.Cards...)
+	return deck, nil
+}
+
+func (m *MemoryDeckStore) DeleteDeck(owner string, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deck, ok := m.decks[id]
+	if !ok || deck.Owner != owner {
+		return ErrDeckNotFound
+	}
+	delete(m.decks, id)
+	return nil
+}
@@ -1,5 +1,7 @@
 package game
 
+import "reflect"
+
 type UnitMove struct {
 	ID int `json:"id"`
 	X  int `json:"x"`
@@ -11,6 +13,10 @@ type UnitUpdate struct {
 	TargetID int    `json:"targetId,omitempty"`
 	HP       int    `json:"hp,omitempty"`
 	Status   string `json:"status,omitempty"`
+	Rank     int    `json:"rank,omitempty"`  // Nuevo rango de veteranía
+	MaxHP    int    `json:"maxHp,omitempty"` // HP máximo cuando cambió (promoción)
+	// Efectos activos cuando cambiaron (lista vacía = se quitaron todos)
+	Effects *[]StatusEffect `json:"effects,omitempty"`
 }
 
 type Delta struct {
@@ -68,8 +74,10 @@ func BuildDelta(prev, curr Snapshot) Delta {
 			})
 		}
 
-		// Detectar cambios de estado (TargetID, HP, Status)
-		if currUnit.TargetID != prevUnit.TargetID || currUnit.HP != prevUnit.HP || currUnit.Status != prevUnit.Status {
+		// Detectar cambios de estado (TargetID, HP, Status, efectos, veteranía)
+		effectsChanged := !reflect.DeepEqual(currUnit.Effects, prevUnit.Effects)
+		promoted := currUnit.Rank != prevUnit.Rank || currUnit.MaxHP != prevUnit.MaxHP
+		if currUnit.TargetID != prevUnit.TargetID || currUnit.HP != prevUnit.HP || currUnit.Status != prevUnit.Status || effectsChanged || promoted {
 			update := UnitUpdate{
 				ID: id,
 			}
@@ -82,6 +90,17 @@ func BuildDelta(prev, curr Snapshot) Delta {
 			if currUnit.Status != prevUnit.Status {
 				update.Status = currUnit.Status
 			}
+			if promoted {
+				update.Rank = currUnit.Rank
+				update.MaxHP = currUnit.MaxHP
+			}
+			if effectsChanged {
+				effects := copyEffects(currUnit.Effects)
+				if effects == nil {
+					effects = []StatusEffect{}
+				}
+				update.Effects = &effects
+			}
 			delta.Updated = append(delta.Updated, update)
 		}
 	}
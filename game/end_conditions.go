@@ -0,0 +1,211 @@
+package game
+
+import (
+	"log/slog"
+	"sort"
+)
+
+// TiebreakRule decide el ganador cuando se alcanza MaxTurns sin que caiga ninguna base
+type TiebreakRule string
+
+const (
+	TiebreakBaseHP         TiebreakRule = "base_hp"         // Mayor porcentaje de HP de la base principal
+	TiebreakStructureValue TiebreakRule = "structure_value" // Mayor HP total de estructuras vivas
+	TiebreakScore          TiebreakRule = "score"           // Mayor daño total infligido en la partida
+	TiebreakNone           TiebreakRule = "none"            // Empate directo
+)
+
+// SuddenDeathMode define qué pasa a partir de SuddenDeathTurn
+type SuddenDeathMode string
+
+const (
+	SuddenDeathAttackBuff SuddenDeathMode = "attack_buff" // Todo el daño de ataque se multiplica
+	SuddenDeathBaseDamage SuddenDeathMode = "base_damage" // Ambas bases reciben daño creciente cada turno
+)
+
+// Motivos de fin de juego además de la caída de una base
+const (
+	EndReasonMaxTurns           = "max_turns"            // Se alcanzó MaxTurns (decide el desempate)
+	EndReasonBothBasesDestroyed = "both_bases_destroyed" // Ambas bases cayeron a la vez (empate)
+)
+
+// SuddenDeathBaseHit describe el daño de muerte súbita sobre una base
+type SuddenDeathBaseHit struct {
+	PlayerID int `json:"playerId"`
+	BaseID   int `json:"baseId"`
+	Damage   int `json:"damage"`
+	HP       int `json:"hp"`
+}
+
+// SuddenDeathEventData es el payload de un evento "sudden_death" (se emite en cada turno activo)
+type SuddenDeathEventData struct {
+	TurnNumber int                  `json:"turnNumber"`
+	Mode       SuddenDeathMode      `json:"mode"`
+	Bases      []SuddenDeathBaseHit `json:"bases,omitempty"` // Solo base_damage
+}
+
+// suddenDeathLocked indica si la muerte súbita está activa en el turno actual (requiere lock tomado)
+func (g *GameState) suddenDeathLocked() bool {
+	return g.Config.SuddenDeathTurn > 0 && g.TurnNumber >= g.Config.SuddenDeathTurn
+}
+
+// suddenDeathMultiplierLocked retorna el multiplicador de daño de ataque por muerte súbita
+// (requiere lock tomado)
+func (g *GameState) suddenDeathMultiplierLocked() float64 {
+	if g.suddenDeathLocked() && g.Config.SuddenDeathMode == SuddenDeathAttackBuff && g.Config.SuddenDeathDamageMultiplier > 0 {
+		return g.Config.SuddenDeathDamageMultiplier
+	}
+	return 1.0
+}
+
+// applySuddenDeathLocked se llama al empezar cada turno. En base_damage todas las bases reciben
+// SuddenDeathBaseDamage × turnos en muerte súbita (ignora escudos y armadura) (requiere lock tomado)
+func (g *GameState) applySuddenDeathLocked() {
+	if !g.suddenDeathLocked() {
+		return
+	}
+	data := SuddenDeathEventData{TurnNumber: g.TurnNumber, Mode: g.Config.SuddenDeathMode}
+	if g.Config.SuddenDeathMode == SuddenDeathBaseDamage {
+		damage := g.Config.SuddenDeathBaseDamage * (g.TurnNumber - g.Config.SuddenDeathTurn + 1)
+		for _, p := range g.seatedPlayersLocked() {
+			base := g.mainBaseLocked(p.ID)
+			if base == nil || base.HP <= 0 || damage <= 0 {
+				continue
+			}
+			base.HP -= damage
+			g.Stats.recordDamage(0, "", base, damage)
+			data.Bases = append(data.Bases, SuddenDeathBaseHit{PlayerID: p.ID, BaseID: base.ID, Damage: damage, HP: base.HP})
+		}
+	}
+	g.emitEventLocked(EventSuddenDeath, data)
+	slog.Info("Sudden death", "tick", g.Tick, "turn", g.TurnNumber, "mode", g.Config.SuddenDeathMode)
+}
+
+// tiebreakScoreLocked calcula el puntaje de desempate de un jugador (requiere lock tomado)
+func (g *GameState) tiebreakScoreLocked(rule TiebreakRule, playerID int) float64 {
+	switch rule {
+	case TiebreakBaseHP:
+		base := g.mainBaseLocked(playerID)
+		if base == nil || base.HP <= 0 || base.MaxHP <= 0 {
+			return 0
+		}
+		return 100 * float64(base.HP) / float64(base.MaxHP)
+	case TiebreakStructureValue:
+		total := 0
+		for _, unit := range g.Units {
+			if unit.PlayerID == playerID && unit.Category == CategoryStructure && unit.HP > 0 {
+				total += unit.HP
+			}
+		}
+		return float64(total)
+	case TiebreakScore:
+		total := 0
+		if p, ok := g.Stats.Players[playerID]; ok {
+			for _, u := range p.Units {
+				total += u.DamageDealt
+			}
+		}
+		return float64(total)
+	}
+	return 0
+}
+
+// tiebreakLocked decide la partida al alcanzar MaxTurns (requiere lock tomado)
+func (g *GameState) tiebreakLocked() *GameEndInfo {
+	rule := g.Config.Tiebreak
+	if rule == "" {
+		rule = TiebreakBaseHP
+	}
+	end := &GameEndInfo{Pending: true, Reason: EndReasonMaxTurns, Tiebreak: rule, Draw: true}
+	if rule == TiebreakNone {
+		return end
+	}
+
+	end.Scores = make(map[int]float64, 2)
+	if g.Config.teamsEnabled() {
+		g.teamTiebreakLocked(end, rule)
+		return end
+	}
+	for _, playerID := range []int{g.HumanPlayerID, g.AIPlayerID} {
+		end.Scores[playerID] = g.tiebreakScoreLocked(rule, playerID)
+	}
+	human, ai := end.Scores[g.HumanPlayerID], end.Scores[g.AIPlayerID]
+	switch {
+	case human > ai:
+		end.Draw, end.WinnerID, end.LoserID = false, g.HumanPlayerID, g.AIPlayerID
+	case ai > human:
+		end.Draw, end.WinnerID, end.LoserID = false, g.AIPlayerID, g.HumanPlayerID
+	}
+	return end
+}
+
+// teamTiebreakLocked decide por equipos: gana el equipo con mayor suma de puntajes de sus
+// jugadores; si la mejor suma está repetida queda empate (requiere lock tomado)
+func (g *GameState) teamTiebreakLocked(end *GameEndInfo, rule TiebreakRule) {
+	totals := make(map[int]float64)
+	first := make(map[int]int)
+	teams := []int{}
+	for _, p := range g.seatedPlayersLocked() {
+		end.Scores[p.ID] = g.tiebreakScoreLocked(rule, p.ID)
+		if _, seen := first[p.Team]; !seen {
+			first[p.Team] = p.ID
+			teams = append(teams, p.Team)
+		}
+		totals[p.Team] += end.Scores[p.ID]
+	}
+	if len(teams) < 2 {
+		return
+	}
+	sort.SliceStable(teams, func(i, j int) bool { return totals[teams[i]] > totals[teams[j]] })
+	winner, loser := teams[0], teams[len(teams)-1]
+	if totals[winner] == totals[teams[1]] {
+		return
+	}
+	end.Draw = false
+	end.WinnerID, end.LoserID = first[winner], first[loser]
+	end.WinnerTeam, end.LoserTeam = winner, loser
+}
+
+// CheckGameEnd evalúa las condiciones de fin de juego: caída de bases (ambas a la vez = empate;
+// con equipos, la última base de un equipo), última oleada derrotada (modo waves) y límite de
+// turnos con desempate. Retorna nil si la partida sigue.
+func (s *GameSimulation) CheckGameEnd() *GameEndInfo {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	g := s.state
+	if g.Config.teamsEnabled() {
+		if end := g.teamsEndLocked(); end != nil {
+			return end
+		}
+		if g.Config.MaxTurns > 0 && g.TurnNumber > g.Config.MaxTurns {
+			return g.tiebreakLocked()
+		}
+		return nil
+	}
+	destroyed := func(baseID int) bool {
+		if baseID == 0 {
+			return false
+		}
+		base, ok := g.Units[baseID]
+		return !ok || base.HP <= 0
+	}
+	humanLost, aiLost := destroyed(g.HumanBaseID), destroyed(g.AIBaseID)
+
+	switch {
+	case humanLost && aiLost:
+		return &GameEndInfo{Pending: true, Draw: true, Reason: EndReasonBothBasesDestroyed}
+	case humanLost:
+		return &GameEndInfo{Pending: true, LoserID: g.HumanPlayerID, WinnerID: g.AIPlayerID, Reason: "human_base_destroyed"}
+	case aiLost:
+		return &GameEndInfo{Pending: true, LoserID: g.AIPlayerID, WinnerID: g.HumanPlayerID, Reason: "ai_base_destroyed"}
+	}
+
+	if g.wavesSurvivedLocked() {
+		return &GameEndInfo{Pending: true, LoserID: g.AIPlayerID, WinnerID: g.HumanPlayerID, Reason: EndReasonWavesSurvived}
+	}
+	if g.Config.MaxTurns > 0 && g.TurnNumber > g.Config.MaxTurns {
+		return g.tiebreakLocked()
+	}
+	return nil
+}
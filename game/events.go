@@ -0,0 +1,176 @@
+package game
+
+// EventType identifica el tipo de evento de juego emitido por la simulación
+type EventType string
+
+const (
+	EventAttack EventType = "attack" // Un ataque impactó (uno o varios objetivos)
+	EventHeal   EventType = "heal"   // Una unidad fue curada o reparada
+
+	EventUnitSpawned  EventType = "unit_spawned"  // Se creó una unidad (carta, generador o base)
+	EventUnitDied     EventType = "unit_died"     // Una unidad murió (se retira en Cleanup)
+	EventCardPlayed   EventType = "card_played"   // Un jugador jugó una carta de su mano
+	EventBasePlaced   EventType = "base_placed"   // Un jugador colocó su base principal
+	EventUnitPromoted EventType = "unit_promoted" // Una unidad subió de rango de veteranía
+
+	EventWallBreached EventType = "wall_breached" // Un segmento de muralla fue destruido
+	EventSpellCast    EventType = "spell_cast"    // Un jugador jugó una carta de hechizo
+	EventFatigue      EventType = "fatigue"       // Un jugador robó con el mazo vacío (FiniteDeck)
+	EventTimeBank     EventType = "time_bank"     // Tiempo restante del banco de un jugador
+	EventSuddenDeath  EventType = "sudden_death"  // Turno en muerte súbita (y daño a las bases)
+	EventWaveStarted  EventType = "wave_started"  // Modo waves: empezó una oleada
+
+	EventControlPointCaptured EventType = "control_point_captured" // Un jugador capturó un punto de control
+
+	EventPauseRequested EventType = "pause_requested" // PvP: un jugador pidió pausa (el rival debe aceptar)
+	EventGamePaused     EventType = "game_paused"     // La partida quedó en pausa
+	EventGameResumed    EventType = "game_resumed"    // La partida se reanudó (jugador o timeout)
+
+	// Eventos privados (solo se envían al dueño de la mano)
+	EventMulligan       EventType = "mulligan"        // El jugador devolvió cartas y robó otras
+	EventCardsDiscarded EventType = "cards_discarded" // Cartas descartadas o quemadas por exceso de mano
+)
+
+// GameEvent es un evento puntual de la simulación que se envía a los clientes
+type GameEvent struct {
+	Seq  int       `json:"seq"` // Número de secuencia de la partida (creciente; ver EventsSince)
+	Type EventType `json:"type"`
+	Tick int       `json:"tick"`
+	Data any       `json:"data"`
+
+	// OwnerID > 0 marca el evento como privado: solo se envía a ese jugador
+	OwnerID int `json:"-"`
+}
+
+// AttackHit describe el daño recibido por una unidad en un ataque
+type AttackHit struct {
+	UnitID   int  `json:"unitId"`
+	PlayerID int  `json:"playerId"`
+	Damage   int  `json:"damage"`
+	HP       int  `json:"hp"`                 // HP restante tras el impacto
+	Friendly bool `json:"friendly,omitempty"` // Fuego amigo
+}
+
+// AttackEventData es el payload de un evento "attack"
+type AttackEventData struct {
+	AttackerID int         `json:"attackerId"`
+	PlayerID   int         `json:"playerId"`
+	TargetID   int         `json:"targetId"`
+	X          int         `json:"x"` // Tile de impacto
+	Y          int         `json:"y"`
+	DamageType DamageType  `json:"damageType,omitempty"`
+	Hits       []AttackHit `json:"hits"`
+}
+
+// HealEventData es el payload de un evento "heal"
+type HealEventData struct {
+	HealerID int `json:"healerId"` // 0 = reparación automática entre turnos
+	TargetID int `json:"targetId"`
+	PlayerID int `json:"playerId"`
+	Amount   int `json:"amount"`
+	HP       int `json:"hp"`
+}
+
+// UnitSpawnedEventData es el payload de un evento "unit_spawned"
+type UnitSpawnedEventData struct {
+	UnitID   int    `json:"unitId"`
+	PlayerID int    `json:"playerId"`
+	UnitType string `json:"unitType"`
+	X        int    `json:"x"`
+	Y        int    `json:"y"`
+	SourceID int    `json:"sourceId,omitempty"` // Generador que la produjo (0 = carta o base)
+}
+
+// UnitDiedEventData es el payload de un evento "unit_died"
+type UnitDiedEventData struct {
+	UnitID         int    `json:"unitId"`
+	PlayerID       int    `json:"playerId"`
+	UnitType       string `json:"unitType"`
+	X              int    `json:"x"`
+	Y              int    `json:"y"`
+	KillerID       int    `json:"killerId,omitempty"`       // Último atacante (0 = hechizo, quemadura, fatiga...)
+	KillerPlayerID int    `json:"killerPlayerId,omitempty"` // Jugador del último atacante
+}
+
+// CardPlayedEventData es el payload de un evento "card_played". Las cartas de unidad van
+// acompañadas de un unit_spawned y los hechizos de un spell_cast en el mismo tick.
+type CardPlayedEventData struct {
+	PlayerID int    `json:"playerId"`
+	Card     string `json:"card"`
+}
+
+// BasePlacedEventData es el payload de un evento "base_placed"
+type BasePlacedEventData struct {
+	PlayerID int `json:"playerId"`
+	BaseID   int `json:"baseId"`
+	X        int `json:"x"`
+	Y        int `json:"y"`
+}
+
+// EventsMessage agrupa los eventos de un tick para enviarlos por WebSocket
+type EventsMessage struct {
+	Type   string      `json:"type"` // "events"
+	Tick   int         `json:"tick"`
+	Events []GameEvent `json:"events"`
+}
+
+// emitEventLocked agrega un evento al buffer del tick (requiere lock tomado)
+func (g *GameState) emitEventLocked(eventType EventType, data any) {
+	g.queueEventLocked(GameEvent{
+		Type: eventType,
+		Tick: g.Tick,
+		Data: data,
+	})
+}
+
+// emitPrivateEventLocked agrega un evento que solo verá el jugador indicado (requiere lock tomado)
+func (g *GameState) emitPrivateEventLocked(playerID int, eventType EventType, data any) {
+	g.queueEventLocked(GameEvent{
+		Type:    eventType,
+		Tick:    g.Tick,
+		Data:    data,
+		OwnerID: playerID,
+	})
+}
+
+// queueEventLocked numera el evento, lo guarda en el buffer de reenvío y lo deja pendiente de
+// envío (requiere lock tomado)
+func (g *GameState) queueEventLocked(e GameEvent) {
+	g.lastEventSeq++
+	e.Seq = g.lastEventSeq
+	g.pendingEvents = append(g.pendingEvents, e)
+	g.recordEventLocked(e)
+}
+
+// SplitPrivateEvents separa los eventos públicos de los privados (agrupados por jugador)
+func SplitPrivateEvents(events []GameEvent) ([]GameEvent, map[int][]GameEvent) {
+	var public []GameEvent
+	private := make(map[int][]GameEvent)
+	for _, e := range events {
+		if e.OwnerID > 0 {
+			private[e.OwnerID] = append(private[e.OwnerID], e)
+		} else {
+			public = append(public, e)
+		}
+	}
+	return public, private
+}
+
+// DrainEvents retorna los eventos emitidos desde la última llamada y resetea el buffer.
+func (g *GameState) DrainEvents() []GameEvent {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	events := g.pendingEvents
+	g.pendingEvents = nil
+	return events
+}
+
+// BuildEventsMessage crea el mensaje de eventos de un tick
+func BuildEventsMessage(tick int, events []GameEvent) EventsMessage {
+	return EventsMessage{
+		Type:   "events",
+		Tick:   tick,
+		Events: events,
+	}
+}
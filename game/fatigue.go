@@ -0,0 +1,52 @@
+package game
+
+import "log/slog"
+
+// FatigueEventData es el payload de un evento "fatigue"
+type FatigueEventData struct {
+	PlayerID int `json:"playerId"`
+	BaseID   int `json:"baseId"`
+	Damage   int `json:"damage"`
+	HP       int `json:"hp"`      // HP restante de la base
+	Fatigue  int `json:"fatigue"` // Robos con el mazo vacío acumulados
+}
+
+// baseIDLocked retorna el ID de la base principal que colocó el jugador (0 = aún no la colocó),
+// aunque ya haya caído (requiere lock tomado)
+func (g *GameState) baseIDLocked(playerID int) int {
+	if p, ok := g.Players[playerID]; ok {
+		return p.BaseID
+	}
+	return 0
+}
+
+// mainBaseLocked retorna la base principal del jugador si existe (requiere lock tomado)
+func (g *GameState) mainBaseLocked(playerID int) *UnitState {
+	baseID := g.baseIDLocked(playerID)
+	if baseID == 0 {
+		return nil
+	}
+	return g.Units[baseID]
+}
+
+// applyFatigueLocked se aplica al robar con el mazo vacío en modo FiniteDeck: cada robo fallido
+// suma 1 al contador de fatiga y daña la base principal por Fatigue × FatigueDamage (ignora
+// escudos y armadura). La base puede morir, lo que termina la partida (requiere lock tomado).
+func (g *GameState) applyFatigueLocked(p *Player) {
+	p.Fatigue++
+	damage := p.Fatigue * g.Config.FatigueDamage
+	base := g.mainBaseLocked(p.ID)
+	if base == nil || base.HP <= 0 || damage <= 0 {
+		return
+	}
+	base.HP -= damage
+	g.Stats.recordDamage(0, "", base, damage)
+	g.emitEventLocked(EventFatigue, FatigueEventData{
+		PlayerID: p.ID,
+		BaseID:   base.ID,
+		Damage:   damage,
+		HP:       base.HP,
+		Fatigue:  p.Fatigue,
+	})
+	slog.Info("Fatigue damage", "tick", g.Tick, "playerId", p.ID, "fatigue", p.Fatigue, "damage", damage, "baseHp", base.HP)
+}
@@ -13,28 +13,22 @@ type Game struct {
 }
 
 func NewGame(id int) *Game {
-	state := NewGameState()
-	simulation := NewGameSimulation(state)
-
-	game := &Game{
-		ID:         id,
-		State:      state,
-		Simulation: simulation,
-		Clock:      NewGameClock(200),
-		Commands:   command.NewCommandQueue(),
-		Snapshot:   nil,
-		Delta:      nil,
-	}
-
-	// Set ticks-per-second into state for DPS-to-ticks calculations
-	state.TicksPerSecond = game.Clock.TicksPerSecond()
-	simulation.BindGame(game)
-	return game
+	return newGameWithState(id, NewGameState())
 }
 
 // NewGameWithConfig crea un nuevo juego con configuración personalizada
 func NewGameWithConfig(id int, config PhaseConfig) *Game {
-	state := NewGameStateWithConfig(config)
+	return newGameWithState(id, NewGameStateWithConfig(config))
+}
+
+// NewGameWithSeed crea un juego reproducible (mismo mapa y mismo azar para una seed dada).
+// Pensado para simulaciones sin red: el llamador avanza la simulación con ProcessTick.
+func NewGameWithSeed(id int, seed int64, config PhaseConfig) *Game {
+	return newGameWithState(id, NewGameStateWithSeedAndConfig(seed, config))
+}
+
+// newGameWithState arma el juego (simulación, reloj y cola de comandos) sobre un estado ya creado
+func newGameWithState(id int, state *GameState) *Game {
 	simulation := NewGameSimulation(state)
 
 	game := &Game{
@@ -1,6 +1,7 @@
 package game
 
 import (
+	"fmt"
 	"math"
 )
 
@@ -30,10 +31,17 @@ type Tile struct {
 	TerrainID int  `json:"terrainId"`
 }
 
+// MapControlPoint es la posición de un punto de control neutral definido por el mapa
+type MapControlPoint struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
 type GameMap struct {
-	Width  int      `json:"width"`
-	Height int      `json:"height"`
-	Tiles  [][]Tile `json:"tiles"`
+	Width         int               `json:"width"`
+	Height        int               `json:"height"`
+	Tiles         [][]Tile          `json:"tiles"`
+	ControlPoints []MapControlPoint `json:"controlPoints,omitempty"`
 }
 
 func NewGameMap(seed int64) *GameMap {
@@ -74,9 +82,82 @@ func NewGameMap(seed int64) *GameMap {
 		}
 	}
 
+	gameMap.placeControlPoints()
 	return gameMap
 }
 
+// placeControlPoints ubica los puntos de control del mapa generado: en el centro y a 1/4 y 3/4
+// de la altura sobre la columna central, cada uno en el tile caminable más cercano
+func (m *GameMap) placeControlPoints() {
+	for _, y := range []int{m.Height / 4, m.Height / 2, 3 * m.Height / 4} {
+		if x, py, ok := m.nearestWalkable(m.Width/2, y, m.Width/4); ok {
+			m.ControlPoints = append(m.ControlPoints, MapControlPoint{X: x, Y: py})
+		}
+	}
+}
+
+// nearestWalkable busca el tile caminable más cercano (Manhattan) a (x, y) hasta maxDist
+func (m *GameMap) nearestWalkable(x, y, maxDist int) (int, int, bool) {
+	for dist := 0; dist <= maxDist; dist++ {
+		for dx := -dist; dx <= dist; dx++ {
+			dy := dist - abs(dx)
+			if m.IsWalkable(x+dx, y+dy) {
+				return x + dx, y + dy, true
+			}
+			if m.IsWalkable(x+dx, y-dy) {
+				return x + dx, y - dy, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// NewGameMapFromRows construye un mapa a partir de filas de texto (una por coordenada Y):
+// '.' pasto, '=' camino, '~' agua y 'C' punto de control (sobre pasto). Todas las filas deben
+// tener el mismo ancho.
+func NewGameMapFromRows(rows []string) (*GameMap, error) {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return nil, fmt.Errorf("map must have at least one row and one column")
+	}
+
+	gameMap := &GameMap{
+		Width:  len(rows[0]),
+		Height: len(rows),
+		Tiles:  make([][]Tile, len(rows)),
+	}
+
+	for y, row := range rows {
+		if len(row) != gameMap.Width {
+			return nil, fmt.Errorf("row %d has width %d, expected %d", y, len(row), gameMap.Width)
+		}
+		gameMap.Tiles[y] = make([]Tile, gameMap.Width)
+		for x, c := range []byte(row) {
+			terrainID := TerrainGrass
+			switch c {
+			case '.':
+				terrainID = TerrainGrass
+			case '=':
+				terrainID = TerrainPath
+			case '~':
+				terrainID = TerrainWater
+			case 'C':
+				terrainID = TerrainGrass
+				gameMap.ControlPoints = append(gameMap.ControlPoints, MapControlPoint{X: x, Y: y})
+			default:
+				return nil, fmt.Errorf("unknown terrain %q at (%d,%d)", c, x, y)
+			}
+			gameMap.Tiles[y][x] = Tile{
+				X:         x,
+				Y:         y,
+				Walkable:  terrainID != TerrainWater,
+				TerrainID: terrainID,
+			}
+		}
+	}
+
+	return gameMap, nil
+}
+
 // Implementación simple de Perlin noise 2D con seed
 func perlinNoiseWithSeed(x, y float64, seed int64) float64 {
 	// Escalar coordenadas
@@ -5,16 +5,24 @@ import (
 	"sync"
 )
 
+// maxFinishedGames es la cantidad de resultados de partidas terminadas que se conservan
+const maxFinishedGames = 100
+
 type GameManager struct {
 	mu     sync.Mutex
 	games  map[int]*Game
 	nextID int
+
+	// Resultados de partidas terminadas (con su resumen de estadísticas), en orden de fin
+	finished      map[int]*GameEndInfo
+	finishedOrder []int
 }
 
 func NewGameManager() *GameManager {
 	return &GameManager{
-		games:  make(map[int]*Game),
-		nextID: 1,
+		games:    make(map[int]*Game),
+		nextID:   1,
+		finished: make(map[int]*GameEndInfo),
 	}
 }
 
@@ -60,7 +68,8 @@ func (gm *GameManager) GetAllGames() []*Game {
 	return list
 }
 
-// EndGame elimina el juego y registra el motivo/derrota
+// EndGame elimina el juego y registra el motivo/derrota. El resultado queda disponible en
+// GetMatchResult (se conservan los últimos maxFinishedGames).
 func (gm *GameManager) EndGame(id int, loserID int, reason string) {
 	gm.mu.Lock()
 	defer gm.mu.Unlock()
@@ -68,5 +77,32 @@ func (gm *GameManager) EndGame(id int, loserID int, reason string) {
 	if g, ok := gm.games[id]; ok {
 		slog.Info("Ending game due to condition", "gameId", id, "loserId", loserID, "reason", reason, "tick", g.State.Tick, "turn", g.State.TurnNumber)
 		delete(gm.games, id)
+
+		end := &GameEndInfo{LoserID: loserID, Reason: reason}
+		g.State.mu.Lock()
+		if g.State.GameEnd != nil && g.State.GameEnd.Pending {
+			// Solo un fin ya decidido; el GameEnd inicial (no pendiente) dejaría el resultado vacío
+			copied := *g.State.GameEnd
+			end = &copied
+		}
+		g.State.mu.Unlock()
+		if end.Stats == nil {
+			end.Stats = g.State.GetMatchStats()
+		}
+		gm.finished[id] = end
+		gm.finishedOrder = append(gm.finishedOrder, id)
+		if len(gm.finishedOrder) > maxFinishedGames {
+			delete(gm.finished, gm.finishedOrder[0])
+			gm.finishedOrder = gm.finishedOrder[1:]
+		}
 	}
 }
+
+// GetMatchResult retorna el resultado de una partida terminada
+func (gm *GameManager) GetMatchResult(id int) (*GameEndInfo, bool) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	end, ok := gm.finished[id]
+	return end, ok
+}
@@ -2,14 +2,17 @@ package game
 
 import (
 	"autobattle-server/command"
+	"errors"
 	"log/slog"
-	"math/rand"
+	"sort"
 )
 
 type GameSimulation struct {
 	state      *GameState
 	game       *Game
 	pathFinder *PathFinder
+	rejected   []RejectedCommand // Comandos rechazados pendientes de drenar
+	breach     breachCache       // Campos de distancias de Block por meta
 }
 
 func NewGameSimulation(state *GameState) *GameSimulation {
@@ -24,12 +27,20 @@ func (s *GameSimulation) BindGame(game *Game) {
 }
 
 func (s *GameSimulation) ProcessTick() {
+	// Con fin de juego pendiente o en pausa el tick no avanza (simulación y temporizadores
+	// congelados): solo se aplican los comandos que pueden cambiar ese estado
+	if s.state.IsGameEndPending() || s.state.IsPaused() {
+		s.applyCommands(s.game.Commands.Drain())
+		s.state.advancePauseClock()
+		return
+	}
+
 	s.state.AdvanceTick()
 
 	// 1️⃣ Aplicar comandos del tick
-	commands := s.game.Commands.Drain()
-	for _, cmd := range commands {
-		s.ApplyCommand(cmd)
+	s.applyCommands(s.game.Commands.Drain())
+	if s.state.IsGameEndPending() || s.state.IsPaused() {
+		return
 	}
 
 	// 1.5️⃣ Procesar fases del juego
@@ -37,6 +48,7 @@ func (s *GameSimulation) ProcessTick() {
 
 	// 2️⃣ Lógica del juego (solo en fase de batalla)
 	if s.state.GetCurrentPhase() == PhaseBattle {
+		s.SpawnWaves()
 		s.Produce()
 
 		// Optimización: UpdateTargets solo cada 5 ticks (reduce cálculos costosos)
@@ -44,9 +56,17 @@ func (s *GameSimulation) ProcessTick() {
 			s.UpdateTargets()
 		}
 
+		s.Effects()
 		s.Move()
-		s.Block()
+		s.UpdateControlPoints()
+
+		// Block busca caminos sobre todo el mapa: se evalúa junto con UpdateTargets
+		if s.state.Tick%5 == 0 {
+			s.Block()
+		}
+
 		s.Attack()
+		s.Support()
 		s.Cleanup()
 	}
 }
@@ -55,12 +75,151 @@ func (s *GameSimulation) ProcessTick() {
 // Comandos
 // =======================
 
-func (s *GameSimulation) ApplyCommand(cmd command.Command) {
+// pauseCommands son los comandos que se aplican con la partida en pausa
+var pauseCommands = map[command.CommandType]bool{
+	command.CommandPause:      true,
+	command.CommandResume:     true,
+	command.CommandSurrender:  true,
+	command.CommandConfirmEnd: true,
+}
+
+// applyCommands aplica los comandos del tick y registra los rechazados. Si un comando pausa o
+// termina la partida, los siguientes se descartan salvo los permitidos en ese estado.
+func (s *GameSimulation) applyCommands(commands []command.Command) {
+	for _, cmd := range commands {
+		err := s.heldCommandError(cmd.Type)
+		if err == nil {
+			err = s.ApplyCommand(cmd)
+		}
+		if err != nil {
+			s.rejected = append(s.rejected, RejectedCommand{Tick: s.state.Tick, Command: cmd, Reason: err.Error()})
+		}
+	}
+}
+
+// heldCommandError retorna un error si el comando no se puede aplicar porque la partida está
+// detenida (fin de juego pendiente o pausa)
+func (s *GameSimulation) heldCommandError(cmdType command.CommandType) error {
+	if s.state.IsGameEndPending() {
+		if cmdType != command.CommandConfirmEnd {
+			return errors.New("game has ended")
+		}
+		return nil
+	}
+	if s.state.IsPaused() && !pauseCommands[cmdType] {
+		return errors.New("game is paused")
+	}
+	return nil
+}
+
+// RejectedCommand registra un comando descartado por la simulación y el motivo
+type RejectedCommand struct {
+	Tick    int             `json:"tick"`
+	Command command.Command `json:"command"`
+	Reason  string          `json:"reason"`
+}
+
+// DrainRejectedCommands retorna los comandos rechazados desde la última llamada y resetea la lista.
+func (s *GameSimulation) DrainRejectedCommands() []RejectedCommand {
+	rejected := s.rejected
+	s.rejected = nil
+	return rejected
+}
+
+// CommandRejectedMessage avisa a un jugador que uno de sus comandos fue descartado y por qué
+type CommandRejectedMessage struct {
+	Type        string              `json:"type"` // "command_rejected"
+	Tick        int                 `json:"tick"`
+	CommandType command.CommandType `json:"commandType"`
+	Reason      string              `json:"reason"`
+}
+
+// BuildCommandRejectedMessage crea el mensaje de un comando rechazado
+func BuildCommandRejectedMessage(r RejectedCommand) CommandRejectedMessage {
+	return CommandRejectedMessage{
+		Type:        "command_rejected",
+		Tick:        r.Tick,
+		CommandType: r.Command.Type,
+		Reason:      r.Reason,
+	}
+}
+
+// dataInt lee un campo numérico del payload JSON de un comando
+func dataInt(data map[string]any, key string) (int, bool) {
+	v, ok := data[key].(float64)
+	if !ok {
+		return 0, false
+	}
+	return int(v), true
+}
+
+// dataString lee un campo de texto del payload JSON de un comando
+func dataString(data map[string]any, key string) (string, bool) {
+	v, ok := data[key].(string)
+	return v, ok
+}
+
+// dataIntSlice lee una lista de números del payload JSON de un comando
+func dataIntSlice(data map[string]any, key string) ([]int, bool) {
+	raw, ok := data[key].([]any)
+	if !ok {
+		return nil, false
+	}
+	out := make([]int, 0, len(raw))
+	for _, v := range raw {
+		n, ok := v.(float64)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, int(n))
+	}
+	return out, true
+}
+
+// dataStringSlice lee una lista de textos del payload JSON de un comando
+func dataStringSlice(data map[string]any, key string) ([]string, bool) {
+	raw, ok := data[key].([]any)
+	if !ok {
+		return nil, false
+	}
+	out := make([]string, 0, len(raw))
+	for _, v := range raw {
+		str, ok := v.(string)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, str)
+	}
+	return out, true
+}
+
+// dataXY lee las coordenadas x/y del payload JSON de un comando
+func dataXY(data map[string]any) (int, int, bool) {
+	x, okX := dataInt(data, "x")
+	y, okY := dataInt(data, "y")
+	return x, y, okX && okY
+}
+
+// anyPhaseCommands no requieren estar en preparation (cada uno valida su propia fase)
+var anyPhaseCommands = map[command.CommandType]bool{
+	command.CommandReady:      true,
+	command.CommandPlaceBase:  true,
+	command.CommandPlayCard:   true,
+	command.CommandMulligan:   true,
+	command.CommandConfirmEnd: true,
+	command.CommandPause:      true,
+	command.CommandResume:     true,
+	command.CommandSurrender:  true,
+}
+
+// ApplyCommand aplica un comando sobre el estado. Retorna un error si el comando fue rechazado.
+func (s *GameSimulation) ApplyCommand(cmd command.Command) error {
 	// Validar que el jugador puede actuar en la fase actual
 	// PlaceBase solo se permite en base_selection, otros comandos en preparation
-	if cmd.Type != command.CommandReady && cmd.Type != command.CommandPlaceBase && !s.state.CanPlayerAct(cmd.PlayerID) {
+	// PlayCard valida la fase según el hechizo y Mulligan solo se permite en base_selection
+	if !anyPhaseCommands[cmd.Type] && !s.state.CanPlayerAct(cmd.PlayerID) {
 		slog.Warn("Command rejected: not in preparation phase", "playerId", cmd.PlayerID, "commandType", cmd.Type, "currentPhase", s.state.GetCurrentPhase())
-		return
+		return errors.New("not in preparation phase")
 	}
 
 	switch cmd.Type {
@@ -69,29 +228,32 @@ func (s *GameSimulation) ApplyCommand(cmd command.Command) {
 		data, ok := cmd.Data.(map[string]any)
 		if !ok {
 			slog.Warn("Invalid place_base data")
-			return
+			return errors.New("invalid place_base data")
 		}
 
-		x := int(data["x"].(float64))
-		y := int(data["y"].(float64))
+		x, y, ok := dataXY(data)
+		if !ok {
+			slog.Warn("Invalid place_base data")
+			return errors.New("invalid place_base data")
+		}
 
 		// Solo permitir colocar base en fase base_selection
 		if s.state.GetCurrentPhase() != PhaseBaseSelection {
 			slog.Warn("Cannot place base outside base_selection phase", "playerId", cmd.PlayerID)
-			return
+			return errors.New("not in base_selection phase")
 		}
 
 		// Verificar que no haya colocado base ya
 		if s.state.HasPlayerPlacedBase(cmd.PlayerID) {
 			slog.Warn("Player already placed base", "playerId", cmd.PlayerID)
-			return
+			return errors.New("base already placed")
 		}
 
 		// Colocar base
 		base := s.state.SpawnUnit(cmd.PlayerID, TypeMainBase, x, y)
 		if base == nil {
 			slog.Warn("Failed to place base", "playerId", cmd.PlayerID, "x", x, "y", y)
-			return
+			return errors.New("invalid base position")
 		}
 
 		s.state.MarkBasePlaced(cmd.PlayerID, base.ID)
@@ -101,41 +263,179 @@ func (s *GameSimulation) ApplyCommand(cmd command.Command) {
 		data, ok := cmd.Data.(map[string]any)
 		if !ok {
 			slog.Warn("Invalid spawn data")
-			return
+			return errors.New("invalid spawn data")
 		}
 
 		slog.Info("SpawnUnit Command", "data", data)
 
-		unitType := data["unitType"].(string)
-		x_position := int(data["x"].(float64))
-		y_position := int(data["y"].(float64))
+		unitType, okType := dataString(data, "unitType")
+		x_position, y_position, okPos := dataXY(data)
+		if !okType || !okPos {
+			slog.Warn("Invalid spawn data")
+			return errors.New("invalid spawn data")
+		}
+
+		if IsSpellCard(unitType) {
+			slog.Warn("Spawn rejected: spell card", "playerId", cmd.PlayerID, "card", unitType)
+			return errors.New("spell cards must be played with play_card")
+		}
 
 		// Verificar que la carta esté en la mano
 		if !s.state.HasCardInHand(cmd.PlayerID, unitType) {
 			slog.Warn("Spawn rejected: card not in hand", "playerId", cmd.PlayerID, "unitType", unitType)
-			return
+			return errors.New("card not in hand")
 		}
 
 		// Intentar spawn primero
-		if s.spawnUnit(cmd.GameID, cmd.PlayerID, unitType, x_position, y_position) {
-			// Solo consumir carta si el spawn fue exitoso
-			s.state.ConsumeCardFromHand(cmd.PlayerID, unitType)
+		if !s.spawnUnit(cmd.GameID, cmd.PlayerID, unitType, x_position, y_position) {
+			return errors.New("invalid spawn position")
 		}
+		// Solo consumir carta si el spawn fue exitoso
+		s.state.ConsumeCardFromHand(cmd.PlayerID, unitType)
 
 	case command.CommandMoveUnit:
 		data, ok := cmd.Data.(map[string]any)
 		if !ok {
 			slog.Warn("Invalid move data")
-			return
+			return errors.New("invalid move data")
 		}
 
-		unitID := int(data["unitId"].(float64))
-		x := int(data["x"].(float64))
-		y := int(data["y"].(float64))
+		unitID, okID := dataInt(data, "unitId")
+		x, y, okPos := dataXY(data)
+		if !okID || !okPos {
+			slog.Warn("Invalid move data")
+			return errors.New("invalid move data")
+		}
 
 		okDest := s.state.SetUnitDestination(cmd.PlayerID, unitID, x, y)
 		if !okDest {
 			slog.Warn("SetUnitDestination failed", "tick", s.state.Tick, "playerId", cmd.PlayerID, "unitId", unitID, "x", x, "y", y)
+			return errors.New("cannot move unit")
+		}
+
+	case command.CommandPlayCard:
+		data, ok := cmd.Data.(map[string]any)
+		if !ok {
+			slog.Warn("Invalid play_card data")
+			return errors.New("invalid play_card data")
+		}
+
+		card, okCard := dataString(data, "card")
+		if !okCard {
+			slog.Warn("Invalid play_card data")
+			return errors.New("invalid play_card data")
+		}
+		def, isSpell := GetSpellDef(card)
+		if !isSpell {
+			return errors.New("not a spell card")
+		}
+		x, y, okPos := dataXY(data)
+		unitID, okUnit := dataInt(data, "unitId")
+		if (def.Targeting == SpellTargetTile && !okPos) || (def.Targeting == SpellTargetUnit && !okUnit) {
+			slog.Warn("Invalid play_card target", "card", card, "targeting", def.Targeting)
+			return errors.New("missing spell target")
+		}
+
+		if err := s.state.PlayCard(cmd.PlayerID, card, x, y, unitID); err != nil {
+			slog.Warn("PlayCard failed", "tick", s.state.Tick, "playerId", cmd.PlayerID, "card", card, "reason", err)
+			return err
+		}
+		// Retirar unidades muertas por el hechizo aunque no sea la fase de batalla
+		s.Cleanup()
+
+	case command.CommandMulligan:
+		data, ok := cmd.Data.(map[string]any)
+		if !ok {
+			slog.Warn("Invalid mulligan data")
+			return errors.New("invalid mulligan data")
+		}
+
+		cards, okCards := dataStringSlice(data, "cards")
+		if !okCards {
+			slog.Warn("Invalid mulligan data")
+			return errors.New("invalid mulligan data")
+		}
+
+		if err := s.state.Mulligan(cmd.PlayerID, cards); err != nil {
+			slog.Warn("Mulligan failed", "tick", s.state.Tick, "playerId", cmd.PlayerID, "cards", cards, "reason", err)
+			return err
+		}
+
+	case command.CommandDiscard:
+		data, ok := cmd.Data.(map[string]any)
+		if !ok {
+			slog.Warn("Invalid discard data")
+			return errors.New("invalid discard data")
+		}
+
+		cards, okCards := dataStringSlice(data, "cards")
+		if !okCards {
+			slog.Warn("Invalid discard data")
+			return errors.New("invalid discard data")
+		}
+
+		if err := s.state.Discard(cmd.PlayerID, cards); err != nil {
+			slog.Warn("Discard failed", "tick", s.state.Tick, "playerId", cmd.PlayerID, "cards", cards, "reason", err)
+			return err
+		}
+
+	case command.CommandMoveGroup:
+		data, ok := cmd.Data.(map[string]any)
+		if !ok {
+			slog.Warn("Invalid move_group data")
+			return errors.New("invalid move_group data")
+		}
+
+		unitIDs, okIDs := dataIntSlice(data, "unitIds")
+		x, y, okPos := dataXY(data)
+		if !okIDs || !okPos {
+			slog.Warn("Invalid move_group data")
+			return errors.New("invalid move_group data")
+		}
+
+		if err := s.state.MoveGroup(cmd.PlayerID, unitIDs, x, y); err != nil {
+			slog.Warn("MoveGroup failed", "tick", s.state.Tick, "playerId", cmd.PlayerID, "unitIds", unitIDs, "x", x, "y", y, "reason", err)
+			return err
+		}
+
+	case command.CommandSetStance:
+		data, ok := cmd.Data.(map[string]any)
+		if !ok {
+			slog.Warn("Invalid set_stance data")
+			return errors.New("invalid set_stance data")
+		}
+
+		unitIDs, okIDs := dataIntSlice(data, "unitIds")
+		stance, okStance := dataString(data, "stance")
+		if !okIDs || !okStance {
+			slog.Warn("Invalid set_stance data")
+			return errors.New("invalid set_stance data")
+		}
+		guardX, guardY, hasGuard := dataXY(data)
+		leash, _ := dataInt(data, "leash")
+
+		if err := s.state.SetStance(cmd.PlayerID, unitIDs, Stance(stance), guardX, guardY, hasGuard, leash); err != nil {
+			slog.Warn("SetStance failed", "tick", s.state.Tick, "playerId", cmd.PlayerID, "unitIds", unitIDs, "stance", stance, "reason", err)
+			return err
+		}
+
+	case command.CommandFocusTarget:
+		data, ok := cmd.Data.(map[string]any)
+		if !ok {
+			slog.Warn("Invalid focus data")
+			return errors.New("invalid focus data")
+		}
+
+		unitID, okID := dataInt(data, "unitId")
+		targetID, okTarget := dataInt(data, "targetId")
+		if !okID || !okTarget {
+			slog.Warn("Invalid focus data")
+			return errors.New("invalid focus data")
+		}
+
+		if err := s.state.SetFocusTarget(cmd.PlayerID, unitID, targetID); err != nil {
+			slog.Warn("SetFocusTarget failed", "tick", s.state.Tick, "playerId", cmd.PlayerID, "unitId", unitID, "targetId", targetID, "reason", err)
+			return err
 		}
 
 	case command.CommandReady:
@@ -149,13 +449,37 @@ func (s *GameSimulation) ApplyCommand(cmd command.Command) {
 
 	case command.CommandConfirmEnd:
 		// Confirmación de fin de juego: permitido aunque no esté en preparation
-		if s.state.ConfirmEndBy(cmd.PlayerID) {
-			slog.Info("Game end confirmed by player", "playerId", cmd.PlayerID)
-			// El GameManager eliminará el juego en el loop principal cuando vea confirmado
-		} else {
+		if !s.state.ConfirmEndBy(cmd.PlayerID) {
 			slog.Warn("Confirm end rejected", "playerId", cmd.PlayerID)
+			return errors.New("no pending game end")
+		}
+		slog.Info("Game end confirmed by player", "playerId", cmd.PlayerID)
+		// El GameManager eliminará el juego en el loop principal cuando vea confirmado
+
+	case command.CommandPause:
+		if err := s.state.RequestPause(cmd.PlayerID); err != nil {
+			slog.Warn("Pause rejected", "playerId", cmd.PlayerID, "reason", err)
+			return err
+		}
+
+	case command.CommandResume:
+		if err := s.state.Resume(cmd.PlayerID); err != nil {
+			slog.Warn("Resume rejected", "playerId", cmd.PlayerID, "reason", err)
+			return err
+		}
+
+	case command.CommandSurrender:
+		if err := s.state.Surrender(cmd.PlayerID); err != nil {
+			slog.Warn("Surrender rejected", "playerId", cmd.PlayerID, "reason", err)
+			return err
 		}
+
+	default:
+		slog.Warn("Unknown command type", "playerId", cmd.PlayerID, "commandType", cmd.Type)
+		return errors.New("unknown command type")
 	}
+
+	return nil
 }
 
 // ProcessPhases maneja la transición automática entre fases
@@ -174,13 +498,18 @@ func (s *GameSimulation) ProcessPhases() {
 			slog.Info("Both bases placed, advancing to TurnStart", "tick", s.state.Tick)
 			s.state.StartFirstTurn() // Iniciar el turno 1
 			s.state.AdvancePhase()
+		} else if s.state.Config.teamsEnabled() {
+			s.placeAISeatBases()
 		} else {
 			// La IA coloca su base SOLO después de que el humano coloque la suya
 			humanPlaced := s.state.HasPlayerPlacedBase(s.state.HumanPlayerID)
 			aiPlaced := s.state.HasPlayerPlacedBase(s.state.AIPlayerID)
 
-			if humanPlaced && !aiPlaced {
-				s.placeAIBase()
+			// Si el asiento humano está controlado por la IA (simulaciones), coloca su base primero
+			if !humanPlaced && s.state.IsPlayerAI(s.state.HumanPlayerID) {
+				s.placeAIBase(s.state.HumanPlayerID)
+			} else if humanPlaced && !aiPlaced {
+				s.placeAIBase(s.state.AIPlayerID)
 			}
 		}
 
@@ -192,6 +521,9 @@ func (s *GameSimulation) ProcessPhases() {
 		}
 
 	case PhasePreparation:
+		// Bancos de tiempo: un jugador sin tiempo pasa a listo
+		s.state.DrainTimeBanks()
+
 		// Fase de preparación: avanzar cuando ambos jugadores estén listos o timeout
 		if s.state.AreBothPlayersReady() {
 			slog.Info("Both players ready, advancing to Battle", "tick", s.state.Tick)
@@ -220,20 +552,43 @@ func (s *GameSimulation) ProcessPhases() {
 	}
 }
 
-// placeAIBase coloca la base de la IA en una posición válida automáticamente
-func (s *GameSimulation) placeAIBase() {
+// placeAISeatBases coloca las bases de los asientos IA de una partida por equipos, SOLO después
+// de que todos los humanos colocaron la suya
+func (s *GameSimulation) placeAISeatBases() {
 	s.state.mu.Lock()
-	aiID := s.state.AIPlayerID
+	pending := []int{}
+	humansPlaced := true
+	for _, p := range s.state.seatedPlayersLocked() {
+		switch {
+		case s.state.mainBaseLocked(p.ID) != nil:
+		case p.IsAI:
+			pending = append(pending, p.ID)
+		default:
+			humansPlaced = false
+		}
+	}
 	s.state.mu.Unlock()
 
+	if !humansPlaced {
+		return
+	}
+	for _, aiID := range pending {
+		s.placeAIBase(aiID)
+	}
+}
+
+// placeAIBase coloca la base de un jugador IA en una posición válida automáticamente
+func (s *GameSimulation) placeAIBase(aiID int) {
 	// Buscar una posición válida aleatoria en el mapa
 	mapWidth := s.state.Map.Width
 	mapHeight := s.state.Map.Height
 
 	// Intentar hasta 100 posiciones aleatorias
 	for attempts := 0; attempts < 100; attempts++ {
-		x := rand.Intn(mapWidth)
-		y := rand.Intn(mapHeight)
+		s.state.mu.Lock()
+		x := s.state.rng.Intn(mapWidth)
+		y := s.state.rng.Intn(mapHeight)
+		s.state.mu.Unlock()
 
 		// Verificar que la posición sea válida
 		if x >= 0 && x < mapWidth && y >= 0 && y < mapHeight {
@@ -255,21 +610,35 @@ func (s *GameSimulation) placeAIBase() {
 func (s *GameSimulation) ProcessAIPreparation(ticksSinceStart int) {
 	s.state.mu.Lock()
 	aiReadyDelay := s.state.Config.AIReadyDelay
-	aiPlayerID := s.state.AIPlayerID
+	wavePlayerID := 0
+	if s.state.Config.wavesEnabled() {
+		wavePlayerID = s.state.AIPlayerID
+	}
+	aiPlayerIDs := make([]int, 0, 2)
+	for _, p := range s.state.Players {
+		if p.IsAI {
+			aiPlayerIDs = append(aiPlayerIDs, p.ID)
+		}
+	}
 	s.state.mu.Unlock()
+	sort.Ints(aiPlayerIDs) // Orden estable para que una misma seed juegue igual
 
 	// La IA se marca como lista después del delay configurado
 	if ticksSinceStart >= aiReadyDelay {
-		s.playAICard()
-		s.state.SetPlayerReady(aiPlayerID, true)
+		for _, aiPlayerID := range aiPlayerIDs {
+			// El atacante de las oleadas no juega cartas
+			if aiPlayerID != wavePlayerID {
+				s.playAICard(aiPlayerID)
+			}
+			s.state.SetPlayerReady(aiPlayerID, true)
+		}
 	}
 }
 
-// playAICard recorre la mano del AI y juega la primera carta que pueda colocar.
-func (s *GameSimulation) playAICard() {
+// playAICard recorre la mano de un jugador IA y juega la primera carta que pueda colocar.
+func (s *GameSimulation) playAICard(aiID int) {
 	// Copiar la mano bajo lock para evitar mutaciones concurrentes
 	s.state.mu.Lock()
-	aiID := s.state.AIPlayerID
 	p, ok := s.state.Players[aiID]
 	if !ok || len(p.Hand) == 0 {
 		s.state.mu.Unlock()
@@ -279,6 +648,14 @@ func (s *GameSimulation) playAICard() {
 	s.state.mu.Unlock()
 
 	for _, card := range handCopy {
+		// Hechizos: la IA solo juega los que no necesitan objetivo
+		if def, isSpell := GetSpellDef(card); isSpell {
+			if def.Targeting == SpellTargetSelf && s.state.PlayCard(aiID, card, 0, 0, 0) == nil {
+				return
+			}
+			continue
+		}
+
 		x, y, okPos := s.state.findSpawnPosition(card, aiID, 50)
 		if !okPos {
 			// Esta carta no tiene posición válida ahora, probar la siguiente
@@ -327,7 +704,7 @@ func (s *GameSimulation) Produce() {
 	s.state.mu.Lock()
 	currentTick := s.state.Tick
 
-	for _, unit := range s.state.Units {
+	for _, unit := range s.state.sortedUnitsLocked() {
 		if !unit.IsGenerator {
 			continue
 		}
@@ -372,10 +749,8 @@ func (s *GameSimulation) Produce() {
 
 	// Ejecutar spawns fuera del lock principal
 	for _, job := range spawns {
-		spawnedUnit := s.state.SpawnUnit(job.playerID, job.unitType, job.x, job.y)
+		spawnedUnit := s.state.SpawnGeneratedUnit(job.genID, job.unitType, job.x, job.y)
 		if spawnedUnit != nil {
-			// Marcar origen de spawn (generador/base)
-			spawnedUnit.SpawnedByID = job.genID
 			slog.Info("Generator spawned unit", "tick", currentTick, "generatorId", job.genID, "unitId", spawnedUnit.ID, "type", job.unitType, "x", job.x, "y", job.y)
 		} else {
 			slog.Warn("Generator failed to spawn unit", "tick", currentTick, "generatorId", job.genID, "type", job.unitType, "x", job.x, "y", job.y)
@@ -383,64 +758,60 @@ func (s *GameSimulation) Produce() {
 	}
 }
 
-// UpdateTargets actualiza el objetivo de unidades móviles hacia el enemigo más cercano
-// dentro de su rango de detección. Si no hay enemigos cercanos, establece la base enemiga como objetivo.
+// UpdateTargets actualiza el objetivo de unidades móviles hacia el objetivo fijado por el
+// jugador o, si no hay, hacia el mejor enemigo según su TargetPriority dentro de su rango de detección. Si no hay enemigos cercanos, establece la base enemiga como objetivo.
 // Si no hay enemigos vivos, se queda con el último target válido.
 func (s *GameSimulation) UpdateTargets() {
 	s.state.mu.Lock()
 	defer s.state.mu.Unlock()
 
-	for _, unit := range s.state.Units {
+	for _, unit := range s.state.sortedUnitsLocked() {
+		// Unidades de soporte: buscan aliados dañados en lugar de enemigos
+		if unit.isHealer() && unit.AttackDamage <= 0 {
+			if unit.CanMove {
+				s.updateHealerTargetLocked(unit)
+			}
+			continue
+		}
+
 		// Solo actualizar targets para unidades que se mueven O que pueden atacar
 		if !unit.CanMove && unit.AttackDamage <= 0 {
 			continue
 		}
 
-		var nearest *UnitState
-		bestDist := 1_000_000
-		for _, candidate := range s.state.Units {
-			if candidate.PlayerID == unit.PlayerID {
-				continue
-			}
-			if candidate.HP <= 0 {
-				continue // Skip dead units
-			}
-			if !candidate.IsTargetable {
-				continue // Skip non-targetable units (like walls)
-			}
-			dx := abs(unit.X - candidate.X)
-			dy := abs(unit.Y - candidate.Y)
-			dist := dx + dy // Manhattan
-			if dist <= unit.DetectionRange && dist < bestDist {
-				bestDist = dist
-				nearest = candidate
-			}
+		// Posturas defensive y hold: no persiguen enemigos fuera de su zona
+		if unit.CanMove && unit.Stance != StanceAggressive {
+			s.updateStanceTargetLocked(unit)
+			continue
 		}
 
-		if nearest != nil {
-			unit.TargetX = nearest.X
-			unit.TargetY = nearest.Y
-			unit.TargetID = nearest.ID
-		} else {
-			// No enemy in detection range - fallback to enemy base
-			enemyBaseID := 0
-			switch unit.PlayerID {
-			case s.state.HumanPlayerID:
-				enemyBaseID = s.state.AIBaseID
-			case s.state.AIPlayerID:
-				enemyBaseID = s.state.HumanBaseID
-			}
+		// Objetivo fijado por el jugador: se persigue aunque esté fuera del rango de detección
+		target := s.state.focusTargetLocked(unit)
+		if target == nil {
+			target = s.state.selectTargetLocked(unit, unit.DetectionRange)
+		}
 
-			if enemyBaseID > 0 {
-				if enemyBase, ok := s.state.Units[enemyBaseID]; ok && enemyBase.HP > 0 {
+		if target != nil {
+			unit.TargetX = target.X
+			unit.TargetY = target.Y
+			unit.TargetID = target.ID
+		} else if unit.HasOrder {
+			// Sin enemigos cerca: seguir la orden del jugador hasta llegar
+			unit.TargetX = unit.OrderX
+			unit.TargetY = unit.OrderY
+			unit.TargetID = 0
+		} else {
+			// No enemy in detection range - fallback to nearest enemy base
+			if enemyBase, placed := s.state.nearestEnemyBaseLocked(unit); placed {
+				if enemyBase != nil {
 					unit.TargetX = enemyBase.X
 					unit.TargetY = enemyBase.Y
 					unit.TargetID = enemyBase.ID
 				} else {
-					// Enemy base is dead, find any enemy unit alive
+					// Enemy bases are dead, find any enemy unit alive
 					var fallbackTarget *UnitState
-					for _, candidate := range s.state.Units {
-						if candidate.PlayerID == unit.PlayerID {
+					for _, candidate := range s.state.sortedUnitsLocked() {
+						if s.state.alliedLocked(candidate.PlayerID, unit.PlayerID) {
 							continue
 						}
 						if candidate.HP > 0 {
@@ -461,6 +832,8 @@ func (s *GameSimulation) UpdateTargets() {
 		if !unit.CanMove {
 			unit.TargetX = unit.X
 			unit.TargetY = unit.Y
+		} else {
+			s.applyBreachTargetLocked(unit)
 		}
 	}
 }
@@ -469,19 +842,41 @@ func (s *GameSimulation) Move() {
 	s.state.mu.Lock()
 	defer s.state.mu.Unlock()
 
-	for _, unit := range s.state.Units {
-		if !unit.CanMove {
+	for _, unit := range s.state.sortedUnitsLocked() {
+		if !unit.CanMove || unit.Stance == StanceHold {
 			unit.Status = "idle"
 			unit.BlockedTicks = 0
 			continue
 		}
 
+		// Orden cumplida: la unidad vuelve a su comportamiento normal
+		if unit.HasOrder && unit.X == unit.OrderX && unit.Y == unit.OrderY {
+			unit.HasOrder = false
+			unit.FormationInterval = 0
+		}
+
 		if unit.X == unit.TargetX && unit.Y == unit.TargetY {
 			unit.Status = "idle"
 			unit.BlockedTicks = 0
 			continue
 		}
 
+		if unit.hasEffect(EffectStun) {
+			unit.Status = "stunned"
+			continue
+		}
+
+		// Las unidades de soporte se detienen al quedar a rango de curación de su objetivo
+		if unit.isHealer() && s.healerInRangeLocked(unit) {
+			if target := s.state.Units[unit.TargetID]; s.state.canHealLocked(unit, target) {
+				unit.Status = "healing"
+			} else {
+				unit.Status = "idle"
+			}
+			unit.BlockedTicks = 0
+			continue
+		}
+
 		if s.state.Tick < unit.NextMoveTick {
 			unit.Status = "waiting"
 			continue
@@ -491,8 +886,8 @@ func (s *GameSimulation) Move() {
 		if unit.AttackDamage > 0 && unit.AttackRange > 0 {
 			// Buscar si hay una unidad enemiga en la posición target
 			var targetUnit *UnitState
-			for _, candidate := range s.state.Units {
-				if candidate.X == unit.TargetX && candidate.Y == unit.TargetY && candidate.PlayerID != unit.PlayerID {
+			for _, candidate := range s.state.sortedUnitsLocked() {
+				if candidate.X == unit.TargetX && candidate.Y == unit.TargetY && !s.state.alliedLocked(candidate.PlayerID, unit.PlayerID) {
 					targetUnit = candidate
 					break
 				}
@@ -517,7 +912,7 @@ func (s *GameSimulation) Move() {
 		if canMove && (newX != unit.X || newY != unit.Y) {
 			unit.X = newX
 			unit.Y = newY
-			unit.NextMoveTick = s.state.Tick + unit.MoveIntervalTicks
+			unit.NextMoveTick = s.state.Tick + unit.moveInterval()
 			unit.Status = "moving"
 			unit.BlockedTicks = 0 // Reset blocked counter on successful move
 		} else {
@@ -533,52 +928,105 @@ func (s *GameSimulation) Move() {
 	}
 }
 
-func (s *GameSimulation) Block() {}
-
-// Attack procesa ataques automáticos para unidades con daño y rango.
+// Attack procesa ataques automáticos para unidades con daño y rango: el objetivo fijado si
+// está a rango o, si no, el mejor enemigo a rango según la TargetPriority de la unidad.
 func (s *GameSimulation) Attack() {
 	s.state.mu.Lock()
 	currentTick := s.state.Tick
 
-	for _, attacker := range s.state.Units {
+	for _, attacker := range s.state.sortedUnitsLocked() {
 		if attacker.AttackDamage <= 0 {
 			continue
 		}
 		if currentTick < attacker.NextAttackTick {
 			continue
 		}
+		if attacker.hasEffect(EffectStun) {
+			continue
+		}
 
-		var target *UnitState
-		bestDist := 1_000_000
-		for _, candidate := range s.state.Units {
-			if candidate.PlayerID == attacker.PlayerID {
-				continue
-			}
-			if !candidate.IsTargetable {
-				continue // Skip non-targetable units (like walls)
-			}
-			dx := abs(attacker.X - candidate.X)
-			dy := abs(attacker.Y - candidate.Y)
-			dist := dx + dy // Manhattan
-			if dist <= attacker.AttackRange && dist < bestDist {
-				bestDist = dist
-				target = candidate
+		// El objetivo fijado tiene prioridad si está a rango; si no, se usa la política del tipo
+		target := s.state.focusTargetLocked(attacker)
+		if target == nil || abs(attacker.X-target.X)+abs(attacker.Y-target.Y) > attacker.AttackRange {
+			target = s.state.selectTargetLocked(attacker, attacker.AttackRange)
+		}
+		// Sin enemigos a rango: golpear la muralla que le sella el camino
+		if target == nil {
+			if wall := s.state.breachTargetLocked(attacker); wall != nil && abs(attacker.X-wall.X)+abs(attacker.Y-wall.Y) <= attacker.AttackRange {
+				target = wall
 			}
 		}
 
-		attacker.NextAttackTick = currentTick + attacker.AttackIntervalTicks
+		attacker.NextAttackTick = currentTick + attacker.scaledInterval(attacker.veteranAttackInterval())
 		if target == nil {
 			continue
 		}
 
-		target.HP -= attacker.AttackDamage
+		hits := s.applyAttackLocked(attacker, target)
 		attacker.Status = "attacking"
-		slog.Info("Attack", "tick", currentTick, "attackerId", attacker.ID, "targetId", target.ID, "damage", attacker.AttackDamage, "targetHP", target.HP)
+		s.state.emitEventLocked(EventAttack, AttackEventData{
+			AttackerID: attacker.ID,
+			PlayerID:   attacker.PlayerID,
+			TargetID:   target.ID,
+			X:          target.X,
+			Y:          target.Y,
+			DamageType: attacker.DamageType,
+			Hits:       hits,
+		})
+		slog.Info("Attack", "tick", currentTick, "attackerId", attacker.ID, "targetId", target.ID, "damage", hits[0].Damage, "damageType", attacker.DamageType, "armorClass", target.ArmorClass, "targetHP", target.HP, "splashHits", len(hits)-1)
 	}
 
 	s.state.mu.Unlock()
 }
 
+// applyAttackLocked aplica el daño de un ataque sobre el objetivo y, si el atacante tiene
+// SplashRadius, sobre las demás unidades elegibles alrededor del tile de impacto.
+// Retorna un impacto por unidad afectada; el primero es siempre el objetivo (requiere lock tomado).
+func (s *GameSimulation) applyAttackLocked(attacker, target *UnitState) []AttackHit {
+	hits := []AttackHit{s.hitLocked(attacker, target, 1.0)}
+	if attacker.SplashRadius <= 0 {
+		return hits
+	}
+
+	friendlyFire := s.state.Config.FriendlyFire
+	for _, victim := range s.state.sortedUnitsLocked() {
+		if victim.ID == target.ID || victim.ID == attacker.ID {
+			continue
+		}
+		if victim.HP <= 0 || !victim.targetable() {
+			continue
+		}
+		if s.state.alliedLocked(victim.PlayerID, attacker.PlayerID) && !friendlyFire {
+			continue
+		}
+		dist := abs(victim.X-target.X) + abs(victim.Y-target.Y)
+		if dist > attacker.SplashRadius {
+			continue
+		}
+		falloff := attacker.SplashFalloff.Factor(dist, attacker.SplashRadius)
+		if falloff <= 0 {
+			continue
+		}
+		hits = append(hits, s.hitLocked(attacker, victim, falloff))
+	}
+	return hits
+}
+
+// hitLocked aplica un impacto individual con el factor de atenuación dado (requiere lock tomado)
+func (s *GameSimulation) hitLocked(attacker, victim *UnitState, falloff float64) AttackHit {
+	damage := s.state.applyDamageLocked(victim, s.state.splashDamageLocked(attacker, victim, falloff))
+	victim.LastAttackerID = attacker.ID
+	s.state.Stats.recordDamage(attacker.PlayerID, attacker.UnitType, victim, damage)
+	s.state.creditDamageLocked(attacker, victim, damage)
+	return AttackHit{
+		UnitID:   victim.ID,
+		PlayerID: victim.PlayerID,
+		Damage:   damage,
+		HP:       victim.HP,
+		Friendly: s.state.alliedLocked(victim.PlayerID, attacker.PlayerID),
+	}
+}
+
 // Cleanup elimina unidades con HP <= 0.
 func (s *GameSimulation) Cleanup() {
 	s.state.mu.Lock()
@@ -588,10 +1036,47 @@ func (s *GameSimulation) Cleanup() {
 			dead = append(dead, id)
 		}
 	}
+	// Registrar las muertes antes de eliminar: el último atacante puede morir en este mismo tick.
+	// Orden por ID para que los eventos unit_died sean deterministas.
+	sort.Ints(dead)
+	for _, id := range dead {
+		unit := s.state.Units[id]
+		killer, ok := s.state.Units[unit.LastAttackerID]
+		killerID := 0
+		if ok {
+			killerID = killer.PlayerID
+			s.state.creditKillLocked(killer, unit)
+		}
+		s.state.Stats.recordDeath(unit, killer)
+		s.state.emitEventLocked(EventUnitDied, UnitDiedEventData{
+			UnitID:         unit.ID,
+			PlayerID:       unit.PlayerID,
+			UnitType:       unit.UnitType,
+			X:              unit.X,
+			Y:              unit.Y,
+			KillerID:       unit.LastAttackerID,
+			KillerPlayerID: killerID,
+		})
+	}
+	wallsBreached := false
 	for _, id := range dead {
 		slog.Info("Removing dead unit", "unitId", id)
+		if unit := s.state.Units[id]; unit.UnitType == TypeWall {
+			wallsBreached = true
+			s.state.emitEventLocked(EventWallBreached, WallBreachedEventData{
+				WallID:       unit.ID,
+				PlayerID:     unit.PlayerID,
+				X:            unit.X,
+				Y:            unit.Y,
+				LineID:       unit.WallLineID,
+				BreachedByID: unit.LastAttackerID,
+			})
+		}
 		delete(s.state.Units, id)
 	}
+	if wallsBreached {
+		s.state.updateWallLinesLocked()
+	}
 
 	// Limpiar TargetID de unidades que apuntaban a unidades muertas
 	if len(dead) > 0 {
@@ -620,24 +1105,11 @@ func (s *GameSimulation) ClearPathfindingCache() {
 	s.pathFinder.ClearCache()
 }
 
-// CheckVictoryConditions verifica si alguna base fue destruida y retorna (gameOver, loserID, reason)
+// CheckVictoryConditions resume CheckGameEnd como (gameOver, loserID, reason); loserID 0 = empate
 func (s *GameSimulation) CheckVictoryConditions() (bool, int, string) {
-	s.state.mu.Lock()
-	defer s.state.mu.Unlock()
-
-	// Verificar si la base humana fue destruida
-	if s.state.HumanBaseID > 0 {
-		if humanBase, ok := s.state.Units[s.state.HumanBaseID]; !ok || humanBase.HP <= 0 {
-			return true, s.state.HumanPlayerID, "human_base_destroyed"
-		}
-	}
-
-	// Verificar si la base AI fue destruida
-	if s.state.AIBaseID > 0 {
-		if aiBase, ok := s.state.Units[s.state.AIBaseID]; !ok || aiBase.HP <= 0 {
-			return true, s.state.AIPlayerID, "ai_base_destroyed"
-		}
+	end := s.CheckGameEnd()
+	if end == nil {
+		return false, 0, ""
 	}
-
-	return false, 0, ""
+	return true, end.LoserID, end.Reason
 }
@@ -2,6 +2,7 @@ package game
 
 import (
 	"math/rand"
+	"sort"
 	"sync"
 	"time"
 )
@@ -27,19 +28,102 @@ type PhaseConfig struct {
 	DisconnectTimeoutSeconds int `json:"disconnectTimeoutSeconds"` // Segundos antes de terminar juego por desconexión
 	CardsPerTurn             int `json:"cardsPerTurn"`             // Cantidad de cartas a robar al inicio de cada turno
 	InitialCardsPerHand      int `json:"initialCardsPerHand"`      // Cantidad de cartas iniciales en la mano
+
+	// Multiplicadores de daño por tipo de daño y clase de armadura (nil = matriz por defecto)
+	DamageMatrix DamageMatrix `json:"damageMatrix,omitempty"`
+
+	// Si el daño en área también afecta a unidades propias/aliadas
+	FriendlyFire bool `json:"friendlyFire"`
+
+	// Porcentaje de MaxHP que recupera cada estructura al empezar un turno nuevo (0 = sin reparación)
+	StructureRepairPercent int `json:"structureRepairPercent"`
+
+	// Multiplicador extra del daño de asedio contra murallas (0 = sin bonus)
+	SiegeWallMultiplier float64 `json:"siegeWallMultiplier"`
+
+	// Mano: tamaño máximo (0 = sin límite), qué hacer con el exceso y mulligans en base_selection
+	MaxHandSize  int          `json:"maxHandSize"`
+	HandOverflow HandOverflow `json:"handOverflow"` // burn (por defecto) o discard
+	MaxMulligans int          `json:"maxMulligans"`
+
+	// Mazo finito: al vaciarse no se rebaraja; cada robo con el mazo vacío daña la base principal
+	// por FatigueDamage × robos fallidos acumulados
+	FiniteDeck    bool `json:"finiteDeck"`
+	FatigueDamage int  `json:"fatigueDamage"`
+
+	// Banco de tiempo por jugador humano (0 = desactivado): se descuenta mientras el jugador no
+	// está listo en preparación y al agotarse pasa a listo. Se recarga al empezar cada
+	// preparación. Se mide en ticks de simulación (ms nominales por tick), no en tiempo real.
+	TimeBankMs          int `json:"timeBankMs"`
+	TimeBankIncrementMs int `json:"timeBankIncrementMs"`
+	TimeBankMaxMs       int `json:"timeBankMaxMs"` // Tope del banco (0 = sin tope)
+
+	// Pausas por jugador y duración máxima de cada una (en segundos reales)
+	MaxPauses       int `json:"maxPauses"`
+	MaxPauseSeconds int `json:"maxPauseSeconds"`
+
+	// Límite de turnos (0 = sin límite): al superarlo decide Tiebreak (o empate)
+	MaxTurns int          `json:"maxTurns"`
+	Tiebreak TiebreakRule `json:"tiebreak"`
+
+	// Muerte súbita desde el turno SuddenDeathTurn (0 = desactivada)
+	SuddenDeathTurn             int             `json:"suddenDeathTurn"`
+	SuddenDeathMode             SuddenDeathMode `json:"suddenDeathMode"`
+	SuddenDeathDamageMultiplier float64         `json:"suddenDeathDamageMultiplier"` // attack_buff
+	SuddenDeathBaseDamage       int             `json:"suddenDeathBaseDamage"`       // base_damage, × turnos en muerte súbita
+
+	// Rangos de veteranía por muertes y daño infligido (vacío = sin veteranía)
+	Veterancy []VeterancyRank `json:"veterancy"`
+
+	// Modo de juego. En waves la IA no juega cartas ni coloca base: lanza una oleada al empezar
+	// cada batalla desde WaveSpawnPoints (vacío = borde opuesto a la base del jugador)
+	Mode            GameMode         `json:"mode"`
+	Waves           []WaveDef        `json:"waves,omitempty"` // Vacío = DefaultWaves
+	WaveSpawnPoints []WaveSpawnPoint `json:"waveSpawnPoints,omitempty"`
+
+	// Puntos de control del mapa: radio y ticks de batalla para capturarlos, y recompensas del
+	// dueño (área de construcción alrededor del punto y cartas extra por turno, 0 = sin ellas)
+	ControlPointRadius       int `json:"controlPointRadius"`
+	ControlPointCaptureTicks int `json:"controlPointCaptureTicks"`
+	ControlPointBuildRange   int `json:"controlPointBuildRange"`
+	ControlPointCardDraws    int `json:"controlPointCardDraws"`
+
+	// Partida por equipos (vacío = 1v1 clásico humano vs IA): un asiento por jugador, con su
+	// equipo y si lo controla la IA. Los aliados no se atacan entre sí; con SharedBuildArea
+	// cada jugador puede construir también en el área de sus aliados
+	Seats           []Seat `json:"seats,omitempty"`
+	SharedBuildArea bool   `json:"sharedBuildArea"`
 }
 
 // DefaultPhaseConfig retorna la configuración por defecto
 func DefaultPhaseConfig() PhaseConfig {
 	return PhaseConfig{
-		TurnStartDuration:        15,  // ~3 segundos
-		PreparationDuration:      150, // ~30 segundos
-		BattleDuration:           300, // ~60 segundos
-		TurnEndDuration:          15,  // ~3 segundos
-		AIReadyDelay:             5,   // ~1 segundo
-		DisconnectTimeoutSeconds: 30,  // 30 segundos de timeout
-		CardsPerTurn:             1,   // 1 carta al inicio de cada turno
-		InitialCardsPerHand:      3,   // 3 cartas iniciales en la mano
+		TurnStartDuration:           15,  // ~3 segundos
+		PreparationDuration:         150, // ~30 segundos
+		BattleDuration:              300, // ~60 segundos
+		TurnEndDuration:             15,  // ~3 segundos
+		AIReadyDelay:                5,   // ~1 segundo
+		DisconnectTimeoutSeconds:    30,  // 30 segundos de timeout
+		CardsPerTurn:                1,   // 1 carta al inicio de cada turno
+		InitialCardsPerHand:         3,   // 3 cartas iniciales en la mano
+		DamageMatrix:                DefaultDamageMatrix(),
+		SiegeWallMultiplier:         1.5,
+		MaxHandSize:                 10,
+		HandOverflow:                HandOverflowBurn,
+		MaxMulligans:                1,
+		FatigueDamage:               10,
+		MaxPauses:                   3,
+		MaxPauseSeconds:             60,
+		Tiebreak:                    TiebreakBaseHP,
+		SuddenDeathMode:             SuddenDeathAttackBuff,
+		SuddenDeathDamageMultiplier: 2,
+		SuddenDeathBaseDamage:       50,
+		Veterancy:                   DefaultVeterancyRanks(),
+		Mode:                        GameModeVersus,
+		ControlPointRadius:          2,
+		ControlPointCaptureTicks:    25, // ~5 segundos
+		ControlPointBuildRange:      4,
+		ControlPointCardDraws:       1,
 	}
 }
 
@@ -78,6 +162,29 @@ type GameState struct {
 
 	// Game end (pending confirmation)
 	GameEnd *GameEndInfo `json:"gameEnd,omitempty"`
+
+	// Pausa (o pausa pedida en PvP); nil si la partida corre normalmente
+	Pause *PauseState `json:"pause,omitempty"`
+
+	// Estadísticas acumuladas de la partida
+	Stats *MatchStats `json:"-"`
+
+	// Progreso de las oleadas (solo modo waves; se crea al consultarlo)
+	Waves *WaveState `json:"-"`
+
+	// Puntos de control (se crean desde el mapa al consultarlos)
+	ControlPoints []*ControlPoint `json:"-"`
+
+	// Eventos emitidos este tick, pendientes de enviar a los clientes
+	pendingEvents []GameEvent
+
+	// Últimos eventos emitidos (acotado a eventLogSize) para reenviarlos al reconectar
+	lastEventSeq int
+	eventLog     []GameEvent
+
+	// Fuente aleatoria propia de la partida (mazos, spawns de IA) para que
+	// una misma seed reproduzca la misma partida
+	rng *rand.Rand
 }
 
 // defaultDeck devuelve un mazo básico con todas las cartas disponibles.
@@ -98,15 +205,27 @@ func defaultDeck() []string {
 	for i := 0; i < 30; i++ {
 		deck = append(deck, TypeWarrior)
 	}
+	// 5 arietes de asedio
+	for i := 0; i < 5; i++ {
+		deck = append(deck, TypeSiegeRam)
+	}
+	// 5 copias de cada hechizo
+	for i := 0; i < 5; i++ {
+		deck = append(deck, SpellFireball)
+		deck = append(deck, SpellRally)
+		deck = append(deck, SpellHealWave)
+		deck = append(deck, SpellReveal)
+	}
+	// 10 copias de cada unidad de soporte
+	for i := 0; i < 10; i++ {
+		deck = append(deck, TypeMedic)
+		deck = append(deck, TypeEngineer)
+	}
 	return deck
 }
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
-func shuffleCards(cards []string) {
-	rand.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+func shuffleCards(rng *rand.Rand, cards []string) {
+	rng.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
 }
 
 type UnitState struct {
@@ -119,11 +238,15 @@ type UnitState struct {
 	MaxHP    int    `json:"maxHp"`
 
 	// Combat properties
-	AttackDamage        int     `json:"attackDamage"`
-	AttackRange         int     `json:"attackRange"`
-	AttackIntervalTicks int     `json:"-"`
-	NextAttackTick      int     `json:"-"`
-	AttackDPS           float64 `json:"attackDps"`
+	AttackDamage        int           `json:"attackDamage"`
+	AttackRange         int           `json:"attackRange"`
+	AttackIntervalTicks int           `json:"-"`
+	NextAttackTick      int           `json:"-"`
+	AttackDPS           float64       `json:"attackDps"`
+	DamageType          DamageType    `json:"damageType,omitempty"`
+	ArmorClass          ArmorClass    `json:"armorClass"`
+	SplashRadius        int           `json:"splashRadius,omitempty"`
+	SplashFalloff       SplashFalloff `json:"splashFalloff,omitempty"`
 
 	// Movement control (not serialized)
 	TargetX           int  `json:"-"`
@@ -134,11 +257,31 @@ type UnitState struct {
 	CanMove           bool `json:"-"`
 	BlockedTicks      int  `json:"-"` // Contador de ticks bloqueado (para detectar deadlocks)
 
+	// Órdenes del jugador: destino que se respeta hasta llegar (en vez de ir a la base enemiga)
+	OrderX            int  `json:"-"`
+	OrderY            int  `json:"-"`
+	HasOrder          bool `json:"hasOrder,omitempty"`
+	FormationInterval int  `json:"-"` // Intervalo del miembro más lento si marcha en formación
+
+	// Meta calculada por UpdateTargets (la muralla a romper puede reemplazarla como objetivo inmediato)
+	GoalX          int `json:"-"`
+	GoalY          int `json:"-"`
+	BreachTargetID int `json:"breachTargetId,omitempty"` // Muralla que está rompiendo (0 si ninguna)
+
+	// Postura (persiste entre turnos)
+	Stance      Stance `json:"stance,omitempty"`
+	GuardX      int    `json:"guardX,omitempty"`
+	GuardY      int    `json:"guardY,omitempty"`
+	LeashRadius int    `json:"leashRadius,omitempty"`
+
 	// Detection
 	DetectionRange int `json:"detectionRange"`
 
 	// Activity/animation state
-	Status string `json:"status"` // idle, moving, waiting, blocked, attacking
+	Status string `json:"status"` // idle, moving, waiting, blocked, attacking, stunned
+
+	// Efectos de estado temporales (slow, stun, burn, shield)
+	Effects []StatusEffect `json:"effects,omitempty"`
 
 	// Generator properties
 	IsGenerator        bool   `json:"isGenerator"`
@@ -152,7 +295,9 @@ type UnitState struct {
 	IsBlocker bool `json:"isBlocker"`
 
 	// Targeting
-	IsTargetable bool `json:"isTargetable"`
+	IsTargetable   bool           `json:"isTargetable"`
+	TargetPriority TargetPriority `json:"targetPriority,omitempty"`
+	FocusTargetID  int            `json:"focusTargetId,omitempty"` // Objetivo fijado por el jugador (0 si no hay)
 
 	// Category for pathfinding
 	Category UnitCategory `json:"category"`
@@ -160,8 +305,33 @@ type UnitState struct {
 	// Build Range - área que esta estructura expande
 	BuildRange int `json:"buildRange"` // Radio de construcción que proporciona
 
+	// Murallas: conexiones con segmentos vecinos (ver WallConnectN...) y línea a la que pertenece
+	WallMask   int `json:"wallMask,omitempty"`
+	WallLineID int `json:"wallLineId,omitempty"`
+
+	// Última unidad que dañó a esta (0 si ninguna)
+	LastAttackerID int `json:"-"`
+
 	// Spawn origin (id del generador/base que creó esta unidad)
 	SpawnedByID int `json:"spawnedById,omitempty"`
+
+	// Support (curación / reparación)
+	HealAmount        int          `json:"healAmount,omitempty"`
+	HealRange         int          `json:"healRange,omitempty"`
+	HealIntervalTicks int          `json:"-"`
+	NextHealTick      int          `json:"-"`
+	HealTargets       UnitCategory `json:"healTargets,omitempty"`
+	HealTargetID      int          `json:"healTargetId,omitempty"` // Unidad curada en el último ciclo (0 si ninguna)
+	HealedTotal       int          `json:"healedTotal,omitempty"`  // HP total restaurado por esta unidad
+
+	// Veteranía (persiste entre turnos): muertes y daño a enemigos acreditados, rango alcanzado y
+	// sus bonus vigentes
+	Kills                int     `json:"kills,omitempty"`
+	DamageDealt          int     `json:"damageDealt,omitempty"`
+	Rank                 int     `json:"rank,omitempty"`
+	RankHPBonus          float64 `json:"-"`
+	RankDamageBonus      float64 `json:"-"`
+	RankAttackSpeedBonus float64 `json:"-"`
 }
 
 // GameEndInfo mantiene el estado de fin de juego pendiente
@@ -171,6 +341,20 @@ type GameEndInfo struct {
 	WinnerID  int    `json:"winnerId"`
 	Reason    string `json:"reason"`
 	Confirmed bool   `json:"confirmed"`
+
+	// Empate (LoserID y WinnerID en 0)
+	Draw bool `json:"draw,omitempty"`
+
+	// Partidas por equipos: equipo ganador y perdedor (WinnerID y LoserID son su jugador de menor ID)
+	WinnerTeam int `json:"winnerTeam,omitempty"`
+	LoserTeam  int `json:"loserTeam,omitempty"`
+
+	// Desempate por límite de turnos: regla usada y puntaje de cada jugador
+	Tiebreak TiebreakRule    `json:"tiebreak,omitempty"`
+	Scores   map[int]float64 `json:"scores,omitempty"`
+
+	// Resumen de estadísticas de la partida al momento del fin
+	Stats *MatchStats `json:"stats,omitempty"`
 }
 
 func NewGameState() *GameState {
@@ -190,6 +374,8 @@ func NewGameStateWithSeed(seed int64) *GameState {
 		PhaseStartTick: 0,                    // Inicializar en tick 0
 		Config:         DefaultPhaseConfig(), // Usar configuración por defecto
 		GameEnd:        &GameEndInfo{Pending: false},
+		Stats:          NewMatchStats(),
+		rng:            rand.New(rand.NewSource(seed)),
 	}
 }
 
@@ -200,6 +386,13 @@ func NewGameStateWithConfig(config PhaseConfig) *GameState {
 	return state
 }
 
+// NewGameStateWithSeedAndConfig crea un estado reproducible (mapa y azar) con configuración personalizada
+func NewGameStateWithSeedAndConfig(seed int64, config PhaseConfig) *GameState {
+	state := NewGameStateWithSeed(seed)
+	state.Config = config
+	return state
+}
+
 // ----- Fin de juego (pendiente/confirmado)
 
 // IsGameEndPending indica si hay fin de juego pendiente de confirmación
@@ -209,21 +402,44 @@ func (g *GameState) IsGameEndPending() bool {
 	return g.GameEnd != nil && g.GameEnd.Pending && !g.GameEnd.Confirmed
 }
 
-// SetPendingEnd marca el fin de juego como pendiente
+// SetPendingEnd marca el fin de juego como pendiente (loserID 0 = empate; con equipos pierde todo
+// el equipo del jugador)
 func (g *GameState) SetPendingEnd(loserID int, reason string) {
+	if loserID == 0 {
+		g.SetPendingEndInfo(&GameEndInfo{Pending: true, Draw: true, Reason: reason})
+		return
+	}
 	g.mu.Lock()
-	defer g.mu.Unlock()
+	var teamEnd *GameEndInfo
+	if g.Config.teamsEnabled() {
+		teamEnd = g.teamForfeitLocked(loserID, reason)
+	}
+	g.mu.Unlock()
+	if teamEnd != nil {
+		g.SetPendingEndInfo(teamEnd)
+		return
+	}
 	winnerID := g.HumanPlayerID
 	if loserID == g.HumanPlayerID {
 		winnerID = g.AIPlayerID
 	}
-	g.GameEnd = &GameEndInfo{
-		Pending:   true,
-		LoserID:   loserID,
-		WinnerID:  winnerID,
-		Reason:    reason,
-		Confirmed: false,
-	}
+	g.SetPendingEndInfo(&GameEndInfo{
+		Pending:  true,
+		LoserID:  loserID,
+		WinnerID: winnerID,
+		Reason:   reason,
+	})
+}
+
+// SetPendingEndInfo marca el fin de juego como pendiente con el resultado completo
+// (ver CheckGameEnd)
+func (g *GameState) SetPendingEndInfo(end *GameEndInfo) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	end.Pending = true
+	end.Confirmed = false
+	end.Stats = g.Stats.Copy()
+	g.GameEnd = end
 }
 
 // ConfirmEndBy confirma fin de juego si hay pendiente; retorna true si se aceptó
@@ -248,8 +464,30 @@ func (g *GameState) AdvanceTick() {
 	g.Tick++
 }
 
+// sortedUnitsLocked devuelve las unidades ordenadas por ID. Las fases del tick recorren las
+// unidades en este orden (y no en el del mapa, que Go aleatoriza) para que una misma seed
+// reproduzca la misma partida (requiere lock tomado).
+func (g *GameState) sortedUnitsLocked() []*UnitState {
+	units := make([]*UnitState, 0, len(g.Units))
+	for _, unit := range g.Units {
+		units = append(units, unit)
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].ID < units[j].ID })
+	return units
+}
+
+// sortedPlayersLocked devuelve los jugadores ordenados por ID (requiere lock tomado)
+func (g *GameState) sortedPlayersLocked() []*Player {
+	players := make([]*Player, 0, len(g.Players))
+	for _, p := range g.Players {
+		players = append(players, p)
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].ID < players[j].ID })
+	return players
+}
+
 // SOLO para red (lectura)
-func (g *GameState) GetSnapshot() GameState {
+func (g *GameState) GetSnapshot() *GameState {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
@@ -261,10 +499,15 @@ func (g *GameState) GetSnapshot() GameState {
 			Hand:      append([]string{}, p.Hand...),
 			DeckCount: p.DeckCount,
 			Connected: p.Connected,
+
+			MulligansUsed: p.MulligansUsed,
+			Fatigue:       p.Fatigue,
+			TimeBankMs:    p.TimeBankMs,
+			PausesUsed:    p.PausesUsed,
 		}
 	}
 
-	return GameState{
+	return &GameState{
 		Tick:             g.Tick,
 		Players:          playersCopy,
 		Units:            g.Units,
@@ -282,46 +525,49 @@ func (g *GameState) GetSnapshot() GameState {
 
 // SOLO para /join
 func (g *GameState) AddPlayer() *Player {
+	return g.AddPlayerWithDeck(nil)
+}
+
+// AddPlayerWithDeck agrega un jugador que usa el mazo indicado (ya validado); nil usa defaultDeck.
+// La IA creada junto al primer jugador siempre usa defaultDeck. En partidas por equipos el jugador
+// ocupa el primer asiento humano libre; retorna nil si no queda ninguno.
+func (g *GameState) AddPlayerWithDeck(deck []string) *Player {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	player := &Player{
-		ID: g.nextPlayerID,
+		ID:         g.nextPlayerID,
+		TimeBankMs: g.Config.TimeBankMs,
+	}
+	if g.Config.teamsEnabled() && !g.seatPlayerLocked(player) {
+		return nil
 	}
-	player.Deck = defaultDeck()
-	shuffleCards(player.Deck)
+	if len(deck) > 0 {
+		player.DeckList = append([]string{}, deck...)
+	}
+	player.Deck = player.deckList()
+	shuffleCards(g.rng, player.Deck)
 	player.DeckCount = len(player.Deck)
 
-	// Dibujar mano inicial (cantidad según config)
-	for i := 0; i < g.Config.InitialCardsPerHand && len(player.Deck) > 0; i++ {
+	// Dibujar mano inicial (cantidad según config, sin superar MaxHandSize)
+	for i := 0; i < g.Config.InitialCardsPerHand && len(player.Deck) > 0 && !g.handFullLocked(player); i++ {
 		g.drawCardLocked(player) // drawCardLocked ya añade a Hand
 	}
 	g.HandUpdatedPlayers = append(g.HandUpdatedPlayers, player.ID)
 
 	g.Players[player.ID] = player
+	if g.Config.teamsEnabled() {
+		return player
+	}
 
 	// Si es el primer jugador (humano), crear también el jugador AI
 	if g.nextPlayerID == 1 {
 		g.HumanPlayerID = player.ID
+		player.Team = 1
 
 		// Crear jugador AI
 		g.nextPlayerID++
-		aiPlayer := &Player{
-			ID:   g.nextPlayerID,
-			IsAI: true,
-		}
-		aiPlayer.Deck = defaultDeck()
-		shuffleCards(aiPlayer.Deck)
-		aiPlayer.DeckCount = len(aiPlayer.Deck)
-
-		// Dibujar mano inicial para IA (cantidad según config)
-		for i := 0; i < g.Config.InitialCardsPerHand && len(aiPlayer.Deck) > 0; i++ {
-			g.drawCardLocked(aiPlayer) // drawCardLocked ya añade a Hand
-		}
-		g.HandUpdatedPlayers = append(g.HandUpdatedPlayers, aiPlayer.ID)
-
-		g.Players[aiPlayer.ID] = aiPlayer
-		g.AIPlayerID = aiPlayer.ID
+		g.AIPlayerID = g.addAIPlayerLocked(g.nextPlayerID, 2).ID
 	}
 
 	g.nextPlayerID++
@@ -338,6 +584,27 @@ func (g *GameState) SetPlayerConnected(playerID int, connected bool) {
 	}
 }
 
+// SetPlayerAI marca a un jugador como controlado por la IA (p.ej. simulaciones IA vs IA)
+func (g *GameState) SetPlayerAI(playerID int, isAI bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if p, ok := g.Players[playerID]; ok {
+		p.IsAI = isAI
+	}
+}
+
+// IsPlayerAI retorna true si el jugador está controlado por la IA
+func (g *GameState) IsPlayerAI(playerID int) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if p, ok := g.Players[playerID]; ok {
+		return p.IsAI
+	}
+	return false
+}
+
 // IsPlayerConnected retorna true si el jugador está marcado como conectado
 func (g *GameState) IsPlayerConnected(playerID int) bool {
 	g.mu.Lock()
@@ -349,18 +616,36 @@ func (g *GameState) IsPlayerConnected(playerID int) bool {
 	return false
 }
 
+// deckList retorna una copia nueva del mazo elegido por el jugador (o defaultDeck)
+func (p *Player) deckList() []string {
+	if len(p.DeckList) == 0 {
+		return defaultDeck()
+	}
+	return append([]string{}, p.DeckList...)
+}
+
 // drawCardLocked roba una carta del mazo del jugador (requiere lock tomado).
-// Si el mazo se vacía, se recrea y baraja automáticamente (mazo infinito).
+// Si el mazo se vacía, se recrea y baraja automáticamente (mazo infinito); con FiniteDeck
+// se aplica fatiga y retorna false. Con la mano llena y HandOverflow burn, la carta robada
+// se quema y retorna false.
 func (g *GameState) drawCardLocked(p *Player) (string, bool) {
+	if len(p.Deck) == 0 && g.Config.FiniteDeck {
+		g.applyFatigueLocked(p)
+		return "", false
+	}
 	if len(p.Deck) == 0 {
 		// Recrear y barajar el mazo cuando se acabe
-		p.Deck = defaultDeck()
-		shuffleCards(p.Deck)
+		p.Deck = p.deckList()
+		shuffleCards(g.rng, p.Deck)
 	}
 	card := p.Deck[0]
 	p.Deck = p.Deck[1:]
-	p.Hand = append(p.Hand, card)
 	p.DeckCount = len(p.Deck)
+	if g.handFullLocked(p) && g.Config.HandOverflow != HandOverflowDiscard {
+		g.emitDiscardLocked(p, []string{card}, DiscardReasonBurn)
+		return card, false
+	}
+	p.Hand = append(p.Hand, card)
 	return card, true
 }
 
@@ -381,6 +666,11 @@ func (g *GameState) HasCardInHand(playerID int, unitType string) bool {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	return g.hasCardInHandLocked(playerID, unitType)
+}
+
+// hasCardInHandLocked verifica si el jugador tiene la carta en su mano (requiere lock tomado)
+func (g *GameState) hasCardInHandLocked(playerID int, unitType string) bool {
 	p, ok := g.Players[playerID]
 	if !ok {
 		return false
@@ -398,6 +688,12 @@ func (g *GameState) ConsumeCardFromHand(playerID int, unitType string) bool {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	return g.consumeCardLocked(playerID, unitType)
+}
+
+// consumeCardLocked remueve una carta jugada de la mano del jugador y la cuenta en las
+// estadísticas (requiere lock tomado)
+func (g *GameState) consumeCardLocked(playerID int, unitType string) bool {
 	p, ok := g.Players[playerID]
 	if !ok {
 		return false
@@ -406,6 +702,8 @@ func (g *GameState) ConsumeCardFromHand(playerID int, unitType string) bool {
 		if c == unitType {
 			p.Hand = append(p.Hand[:i], p.Hand[i+1:]...)
 			g.HandUpdatedPlayers = append(g.HandUpdatedPlayers, playerID)
+			g.Stats.recordCardPlayed(playerID, unitType)
+			g.emitEventLocked(EventCardPlayed, CardPlayedEventData{PlayerID: playerID, Card: unitType})
 			return true
 		}
 	}
@@ -416,8 +714,10 @@ func (g *GameState) ConsumeCardFromHand(playerID int, unitType string) bool {
 // Retorna lista de playerIDs que robaron cartas.
 func (g *GameState) drawForAllPlayersLocked() []int {
 	updated := []int{}
-	for _, p := range g.Players {
-		for i := 0; i < g.Config.CardsPerTurn; i++ {
+	for _, p := range g.sortedPlayersLocked() {
+		// Cartas extra por cada punto de control que tiene el jugador
+		draws := g.Config.CardsPerTurn + g.Config.ControlPointCardDraws*g.ownedControlPointsLocked(p.ID)
+		for i := 0; i < draws; i++ {
 			if _, ok := g.drawCardLocked(p); ok {
 				// Solo agregar el playerID una vez, aunque haya robado múltiples cartas
 				if i == 0 {
@@ -442,32 +742,86 @@ func (g *GameState) AdvancePhase() {
 	case PhaseTurnStart:
 		// Ya en turno, solo pasar a preparación después de la animación breve
 		g.CurrentPhase = PhasePreparation
-		g.HumanPlayerReady = false
-		g.AIPlayerReady = false
+		g.resetReadyLocked()
+		g.topUpTimeBanksLocked()
 
 	case PhasePreparation:
+		// Con HandOverflow discard, lo que no se descartó a tiempo se quema al empezar la batalla
+		g.burnHandOverflowLocked()
 		g.CurrentPhase = PhaseBattle
+		g.startWaveLocked()
 
 	case PhaseBattle:
 		g.CurrentPhase = PhaseTurnEnd
 
 	case PhaseTurnEnd:
+		// Reparación de estructuras entre turnos (si está configurada)
+		g.repairStructuresLocked()
+
 		// Nuevo turno: robar carta al entrar en turn_start
 		updated := g.drawForAllPlayersLocked()
 		g.HandUpdatedPlayers = updated
 		g.CurrentPhase = PhaseTurnStart
 		g.TurnNumber++
+		g.applySuddenDeathLocked()
 	}
 
 	g.PhaseStartTick = g.Tick
 	g.PhaseChangedThisTick = true
 }
 
+// ForcePhase salta directamente a una fase (escenarios y herramientas), reiniciando su temporizador
+func (g *GameState) ForcePhase(phase GamePhase) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.CurrentPhase = phase
+	if phase == PhasePreparation {
+		g.resetReadyLocked()
+	}
+	if phase != PhaseBaseSelection && g.TurnNumber == 0 {
+		g.TurnNumber = 1
+	}
+	g.PhaseStartTick = g.Tick
+	g.PhaseChangedThisTick = true
+}
+
 // SetPlayerReady marca al jugador como listo en la fase de preparación
 func (g *GameState) SetPlayerReady(playerID int, ready bool) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	g.setReadyLocked(playerID, ready)
+
+	// El banco de tiempo se detiene: informar el tiempo que le quedó
+	if p, ok := g.Players[playerID]; ok && g.Config.timeBanksEnabled() && g.CurrentPhase == PhasePreparation {
+		g.emitTimeBankLocked(p, false)
+	}
+}
+
+// AreBothPlayersReady verifica si todos los jugadores de la partida están listos
+func (g *GameState) AreBothPlayersReady() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.Config.teamsEnabled() {
+		return g.HumanPlayerReady && g.AIPlayerReady
+	}
+	players := g.seatedPlayersLocked()
+	for _, p := range players {
+		if !p.Ready {
+			return false
+		}
+	}
+	return len(players) > 0
+}
+
+// setReadyLocked actualiza el flag de listo del jugador (y los flags del 1v1 clásico)
+// (requiere lock tomado)
+func (g *GameState) setReadyLocked(playerID int, ready bool) {
+	if p, ok := g.Players[playerID]; ok {
+		p.Ready = ready
+	}
 	switch playerID {
 	case g.HumanPlayerID:
 		g.HumanPlayerReady = ready
@@ -476,12 +830,13 @@ func (g *GameState) SetPlayerReady(playerID int, ready bool) {
 	}
 }
 
-// AreBothPlayersReady verifica si ambos jugadores están listos
-func (g *GameState) AreBothPlayersReady() bool {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-
-	return g.HumanPlayerReady && g.AIPlayerReady
+// resetReadyLocked desmarca a todos los jugadores al empezar la preparación (requiere lock tomado)
+func (g *GameState) resetReadyLocked() {
+	for _, p := range g.Players {
+		p.Ready = false
+	}
+	g.HumanPlayerReady = false
+	g.AIPlayerReady = false
 }
 
 // CanPlayerAct verifica si un jugador puede realizar acciones en la fase actual
@@ -498,25 +853,27 @@ func (g *GameState) HasPlayerPlacedBase(playerID int) bool {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	switch playerID {
-	case g.HumanPlayerID:
-		return g.HumanBaseID > 0 && g.Units[g.HumanBaseID] != nil
-	case g.AIPlayerID:
-		return g.AIBaseID > 0 && g.Units[g.AIBaseID] != nil
-	}
-	return false
+	return g.mainBaseLocked(playerID) != nil
 }
 
-// BothBasesPlaced verifica si ambos jugadores colocaron sus bases
+// BothBasesPlaced verifica si todos los jugadores colocaron sus bases
 func (g *GameState) BothBasesPlaced() bool {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	// Verificar que ambas bases existan como unidades en el mapa
-	humanBaseExists := g.HumanBaseID > 0 && g.Units[g.HumanBaseID] != nil
-	aiBaseExists := g.AIBaseID > 0 && g.Units[g.AIBaseID] != nil
+	// En modo oleadas el atacante neutral no tiene base
+	if g.Config.wavesEnabled() {
+		return g.mainBaseLocked(g.HumanPlayerID) != nil
+	}
 
-	return humanBaseExists && aiBaseExists
+	// Verificar que todas las bases existan como unidades en el mapa
+	players := g.seatedPlayersLocked()
+	for _, p := range players {
+		if g.mainBaseLocked(p.ID) == nil {
+			return false
+		}
+	}
+	return len(players) > 0
 }
 
 // MarkBasePlaced marca que un jugador colocó su base
@@ -524,12 +881,18 @@ func (g *GameState) MarkBasePlaced(playerID int, baseID int) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	if p, ok := g.Players[playerID]; ok {
+		p.BaseID = baseID
+	}
 	switch playerID {
 	case g.HumanPlayerID:
 		g.HumanBaseID = baseID
 	case g.AIPlayerID:
 		g.AIBaseID = baseID
 	}
+	if base, ok := g.Units[baseID]; ok {
+		g.emitEventLocked(EventBasePlaced, BasePlacedEventData{PlayerID: playerID, BaseID: baseID, X: base.X, Y: base.Y})
+	}
 }
 
 // StartFirstTurn inicia el turno 1 después de colocar las bases
@@ -575,6 +938,30 @@ func (g *GameState) SpawnUnit(playerID int, unitType string, x, y int) *UnitStat
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	return g.spawnUnitLocked(playerID, unitType, x, y, 0)
+}
+
+// SpawnGeneratedUnit crea una unidad producida por el generador indicado, con las mismas
+// validaciones que SpawnUnit. La unidad queda marcada con SpawnedByID y se cuenta en la
+// producción del generador.
+func (g *GameState) SpawnGeneratedUnit(generatorID int, unitType string, x, y int) *UnitState {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	generator, ok := g.Units[generatorID]
+	if !ok {
+		return nil
+	}
+	unit := g.spawnUnitLocked(generator.PlayerID, unitType, x, y, generatorID)
+	if unit != nil {
+		g.Stats.recordGenerated(generator)
+	}
+	return unit
+}
+
+// spawnUnitLocked valida terreno, ocupación y área controlada y crea la unidad
+// (requiere lock tomado)
+func (g *GameState) spawnUnitLocked(playerID int, unitType string, x, y, sourceID int) *UnitState {
 	// Validar posición (terreno + ocupación)
 	if !g.canUnitTypeEnter(unitType, -1, x, y) {
 		return nil
@@ -585,38 +972,43 @@ func (g *GameState) SpawnUnit(playerID int, unitType string, x, y int) *UnitStat
 		return nil
 	}
 
+	return g.createUnitLocked(playerID, unitType, x, y, sourceID)
+}
+
+// PlaceUnit crea una unidad validando solo terreno y ocupación, sin exigir área controlada.
+// Pensado para escenarios de prueba y herramientas que preparan el tablero directamente.
+func (g *GameState) PlaceUnit(playerID int, unitType string, x, y int) *UnitState {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.canUnitTypeEnter(unitType, -1, x, y) {
+		return nil
+	}
+
+	return g.createUnitLocked(playerID, unitType, x, y, 0)
+}
+
+// createUnitLocked instancia la unidad ya validada, la registra y emite "unit_spawned".
+// sourceID es el generador que la produjo (0 = carta o base) (requiere lock tomado).
+func (g *GameState) createUnitLocked(playerID int, unitType string, x, y, sourceID int) *UnitState {
 	unit := &UnitState{
-		ID:       g.nextUnitID,
-		PlayerID: playerID,
-		UnitType: unitType,
-		X:        x,
-		Y:        y,
-		HP:       100,
+		ID:          g.nextUnitID,
+		PlayerID:    playerID,
+		UnitType:    unitType,
+		X:           x,
+		Y:           y,
+		HP:          100,
+		SpawnedByID: sourceID,
 	}
 
 	// Initialize stats based on unit type
 	g.applyUnitStats(unit)
 
-	// Set default target: enemy base position (if available), otherwise current position
+	// Set default target: nearest enemy base position (if available), otherwise current position
 	if unit.CanMove {
-		// Determine enemy base ID depending on the spawning player's side
-		enemyBaseID := 0
-		switch playerID {
-		case g.HumanPlayerID:
-			enemyBaseID = g.AIBaseID
-		case g.AIPlayerID:
-			enemyBaseID = g.HumanBaseID
-		}
-
-		if enemyBaseID > 0 {
-			if enemyBase, ok := g.Units[enemyBaseID]; ok {
-				unit.TargetX = enemyBase.X
-				unit.TargetY = enemyBase.Y
-			} else {
-				// Fallback to current position if enemy base not found yet
-				unit.TargetX = unit.X
-				unit.TargetY = unit.Y
-			}
+		if enemyBase, _ := g.nearestEnemyBaseLocked(unit); enemyBase != nil {
+			unit.TargetX = enemyBase.X
+			unit.TargetY = enemyBase.Y
 		} else {
 			// Fallback to current position if enemy base not assigned yet
 			unit.TargetX = unit.X
@@ -627,8 +1019,21 @@ func (g *GameState) SpawnUnit(playerID int, unitType string, x, y int) *UnitStat
 		unit.TargetX = unit.X
 		unit.TargetY = unit.Y
 	}
+	unit.GoalX, unit.GoalY = unit.TargetX, unit.TargetY
 	g.Units[unit.ID] = unit
 	g.nextUnitID++
+	g.Stats.recordSpawn(unit)
+	g.emitEventLocked(EventUnitSpawned, UnitSpawnedEventData{
+		UnitID:   unit.ID,
+		PlayerID: playerID,
+		UnitType: unitType,
+		X:        x,
+		Y:        y,
+		SourceID: sourceID,
+	})
+	if unit.UnitType == TypeWall {
+		g.updateWallLinesLocked()
+	}
 
 	return unit
 }
@@ -671,9 +1076,11 @@ func (g *GameState) SetUnitDestination(playerID, unitID, x, y int) bool {
 	if !unit.CanMove {
 		return false
 	}
+	if unit.Stance == StanceHold {
+		return false
+	}
 	// Destination can be any tile; step validation happens each move tick
-	unit.TargetX = x
-	unit.TargetY = y
+	g.giveOrderLocked(unit, x, y)
 	return true
 }
 
@@ -688,6 +1095,10 @@ func (g *GameState) applyUnitStats(unit *UnitState) {
 
 	// Aplicar propiedades de movimiento
 	unit.CanMove = stats.CanMove
+	if unit.CanMove {
+		unit.Stance = StanceAggressive
+		unit.LeashRadius = DefaultLeashRadius
+	}
 	unit.MoveIntervalTicks = stats.MoveIntervalTicks
 	if stats.CanMove {
 		unit.NextMoveTick = g.Tick + stats.MoveIntervalTicks
@@ -705,6 +1116,10 @@ func (g *GameState) applyUnitStats(unit *UnitState) {
 	unit.AttackDamage = stats.AttackDamage
 	unit.AttackRange = stats.AttackRange
 	unit.AttackDPS = stats.AttackDPS
+	unit.DamageType = stats.DamageType
+	unit.ArmorClass = stats.ArmorClass
+	unit.SplashRadius = stats.SplashRadius
+	unit.SplashFalloff = stats.SplashFalloff
 	// Si hay DPS configurado, calcular intervalo por ticks en base a AttackDamage
 	if unit.AttackDPS > 0 && unit.AttackDamage > 0 {
 		tps := g.TicksPerSecond
@@ -743,10 +1158,23 @@ func (g *GameState) applyUnitStats(unit *UnitState) {
 
 	// Aplicar propiedades de targeting
 	unit.IsTargetable = stats.IsTargetable
+	unit.TargetPriority = stats.TargetPriority
+	if unit.TargetPriority == "" {
+		unit.TargetPriority = PriorityNearest
+	}
 
 	// Aplicar rango de construcción
 	unit.BuildRange = stats.BuildRange
 
+	// Aplicar propiedades de soporte
+	unit.HealAmount = stats.HealAmount
+	unit.HealRange = stats.HealRange
+	unit.HealIntervalTicks = stats.HealIntervalTicks
+	unit.HealTargets = stats.HealTargets
+	if unit.HealAmount > 0 {
+		unit.NextHealTick = g.Tick + unit.HealIntervalTicks
+	}
+
 	// Estado inicial
 	unit.Status = "idle"
 }
@@ -808,24 +1236,17 @@ func (g *GameState) canUnitTypeEnter(unitType string, skipUnitID int, x, y int)
 }
 
 // isWithinControlledArea verifica si una posición está dentro del área controlada por un jugador.
-// El área controlada está determinada por la base principal y las estructuras con BuildRange > 0.
+// El área controlada está determinada por la base principal y las estructuras con BuildRange > 0
+// (con SharedBuildArea, también las de sus aliados).
 func (g *GameState) isWithinControlledArea(playerID int, x, y int) bool {
 	// Si el jugador no tiene base aún, permitir spawneo libre (para colocar la base inicial)
-	baseID := 0
-	switch playerID {
-	case g.HumanPlayerID:
-		baseID = g.HumanBaseID
-	case g.AIPlayerID:
-		baseID = g.AIBaseID
-	}
-
-	if baseID == 0 {
+	if g.baseIDLocked(playerID) == 0 {
 		return true // Permite colocar la base inicial en cualquier lugar
 	}
 
 	// Verificar si está dentro del rango de alguna estructura del jugador
 	for _, unit := range g.Units {
-		if unit.PlayerID != playerID {
+		if !g.buildsForLocked(unit.PlayerID, playerID) {
 			continue
 		}
 		if unit.HP <= 0 {
@@ -851,7 +1272,8 @@ func (g *GameState) isWithinControlledArea(playerID int, x, y int) bool {
 		}
 	}
 
-	return false
+	// Los puntos de control capturados también extienden el área
+	return g.withinControlPointAreaLocked(playerID, x, y)
 }
 
 // isTileAllowedForUnit checks terrain and blocking constraints for the given unit.
@@ -866,8 +1288,8 @@ func (g *GameState) findSpawnPosition(unitType string, playerID int, attempts in
 	defer g.mu.Unlock()
 
 	for i := 0; i < attempts; i++ {
-		x := rand.Intn(g.Map.Width)
-		y := rand.Intn(g.Map.Height)
+		x := g.rng.Intn(g.Map.Width)
+		y := g.rng.Intn(g.Map.Height)
 
 		// Validar terreno y ocupación
 		if !g.canUnitTypeEnter(unitType, -1, x, y) {
@@ -0,0 +1,148 @@
+package game
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// HandOverflow define qué pasa con las cartas que exceden MaxHandSize
+type HandOverflow string
+
+const (
+	HandOverflowBurn    HandOverflow = "burn"    // La carta robada con la mano llena se quema (por defecto)
+	HandOverflowDiscard HandOverflow = "discard" // Se puede exceder; el jugador descarta con discard antes de la batalla
+)
+
+// Motivos de un evento "cards_discarded"
+const (
+	DiscardReasonDiscard = "discard" // El jugador las descartó con el comando discard
+	DiscardReasonBurn    = "burn"    // Se quemaron por exceder el tamaño máximo de mano
+)
+
+// MulliganEventData es el payload de un evento privado "mulligan"
+type MulliganEventData struct {
+	PlayerID int      `json:"playerId"`
+	Returned []string `json:"returned"` // Cartas devueltas al mazo
+	Drawn    []string `json:"drawn"`    // Cartas robadas en su lugar
+}
+
+// CardsDiscardedEventData es el payload de un evento privado "cards_discarded"
+type CardsDiscardedEventData struct {
+	PlayerID int      `json:"playerId"`
+	Cards    []string `json:"cards"`
+	Reason   string   `json:"reason"`
+}
+
+// handFullLocked indica si la mano alcanzó MaxHandSize (requiere lock tomado)
+func (g *GameState) handFullLocked(p *Player) bool {
+	return g.Config.MaxHandSize > 0 && len(p.Hand) >= g.Config.MaxHandSize
+}
+
+// emitDiscardLocked notifica solo al dueño las cartas que dejaron su mano (requiere lock tomado)
+func (g *GameState) emitDiscardLocked(p *Player, cards []string, reason string) {
+	g.HandUpdatedPlayers = append(g.HandUpdatedPlayers, p.ID)
+	g.emitPrivateEventLocked(p.ID, EventCardsDiscarded, CardsDiscardedEventData{
+		PlayerID: p.ID,
+		Cards:    cards,
+		Reason:   reason,
+	})
+	slog.Info("Cards discarded", "tick", g.Tick, "playerId", p.ID, "cards", cards, "reason", reason)
+}
+
+// removeFromHandLocked quita de la mano una carta por cada elemento de cards. Valida todo antes de
+// modificar: si falta alguna copia no quita ninguna y retorna false (requiere lock tomado).
+func removeFromHandLocked(p *Player, cards []string) bool {
+	remaining := append([]string{}, p.Hand...)
+	for _, card := range cards {
+		found := false
+		for i, c := range remaining {
+			if c == card {
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	p.Hand = remaining
+	return true
+}
+
+// Mulligan devuelve al mazo las cartas elegidas de la mano inicial, baraja y roba la misma
+// cantidad. Solo se permite en base_selection y hasta MaxMulligans veces por jugador.
+func (g *GameState) Mulligan(playerID int, cards []string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, ok := g.Players[playerID]
+	if !ok {
+		return errors.New("player not found")
+	}
+	if g.CurrentPhase != PhaseBaseSelection {
+		return errors.New("not in base_selection phase")
+	}
+	if p.MulligansUsed >= g.Config.MaxMulligans {
+		return errors.New("no mulligans left")
+	}
+	if len(cards) == 0 {
+		return errors.New("no cards")
+	}
+	if !removeFromHandLocked(p, cards) {
+		return errors.New("card not in hand")
+	}
+
+	p.Deck = append(p.Deck, cards...)
+	shuffleCards(g.rng, p.Deck)
+	drawn := make([]string, 0, len(cards))
+	for range cards {
+		if card, ok := g.drawCardLocked(p); ok {
+			drawn = append(drawn, card)
+		}
+	}
+	p.MulligansUsed++
+	g.HandUpdatedPlayers = append(g.HandUpdatedPlayers, playerID)
+	g.emitPrivateEventLocked(playerID, EventMulligan, MulliganEventData{
+		PlayerID: playerID,
+		Returned: append([]string{}, cards...),
+		Drawn:    drawn,
+	})
+	slog.Info("Mulligan", "playerId", playerID, "returned", len(cards), "drawn", len(drawn))
+	return nil
+}
+
+// Discard descarta cartas de la mano del jugador (no vuelven al mazo)
+func (g *GameState) Discard(playerID int, cards []string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, ok := g.Players[playerID]
+	if !ok {
+		return errors.New("player not found")
+	}
+	if len(cards) == 0 {
+		return errors.New("no cards")
+	}
+	if !removeFromHandLocked(p, cards) {
+		return errors.New("card not in hand")
+	}
+	g.emitDiscardLocked(p, append([]string{}, cards...), DiscardReasonDiscard)
+	return nil
+}
+
+// burnHandOverflowLocked quema las últimas cartas robadas que exceden MaxHandSize
+// (requiere lock tomado)
+func (g *GameState) burnHandOverflowLocked() {
+	if g.Config.MaxHandSize <= 0 {
+		return
+	}
+	for _, p := range g.sortedPlayersLocked() {
+		if len(p.Hand) <= g.Config.MaxHandSize {
+			continue
+		}
+		burned := append([]string{}, p.Hand[g.Config.MaxHandSize:]...)
+		p.Hand = p.Hand[:g.Config.MaxHandSize]
+		g.emitDiscardLocked(p, burned, DiscardReasonBurn)
+	}
+}
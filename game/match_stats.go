@@ -0,0 +1,142 @@
+package game
+
+// UnitTypeStats acumula contadores de un tipo de unidad (o hechizo) para un jugador
+type UnitTypeStats struct {
+	Produced    int `json:"produced"`            // Unidades creadas (cartas y generadores)
+	Lost        int `json:"lost"`                // Unidades de este tipo que murieron
+	DamageDealt int `json:"damageDealt"`         // Daño infligido a enemigos por este tipo
+	DamageTaken int `json:"damageTaken"`         // Daño recibido por unidades de este tipo
+	Generated   int `json:"generated,omitempty"` // Unidades producidas por generadores de este tipo
+	Kills       int `json:"kills"`               // Unidades enemigas rematadas por este tipo
+	Promotions  int `json:"promotions"`          // Ascensos de veteranía de unidades de este tipo
+}
+
+// PlayerMatchStats agrupa las estadísticas de un jugador. Los totales se calculan en Copy.
+type PlayerMatchStats struct {
+	Units               map[string]*UnitTypeStats `json:"units"`
+	CardsPlayed         map[string]int            `json:"cardsPlayed"`
+	StructuresDestroyed int                       `json:"structuresDestroyed"` // Estructuras enemigas destruidas
+
+	DamageDealt     int `json:"damageDealt"`
+	DamageTaken     int `json:"damageTaken"`
+	UnitsProduced   int `json:"unitsProduced"`
+	UnitsLost       int `json:"unitsLost"`
+	GeneratorOutput int `json:"generatorOutput"`
+	Kills           int `json:"kills"`
+	Promotions      int `json:"promotions"`
+}
+
+// MatchStats mantiene las estadísticas de la partida (requiere lock del GameState)
+type MatchStats struct {
+	Players map[int]*PlayerMatchStats `json:"players"`
+}
+
+func NewMatchStats() *MatchStats {
+	return &MatchStats{
+		Players: make(map[int]*PlayerMatchStats),
+	}
+}
+
+// playerLocked retorna (creando si hace falta) las estadísticas de un jugador
+func (m *MatchStats) playerLocked(playerID int) *PlayerMatchStats {
+	p, ok := m.Players[playerID]
+	if !ok {
+		p = &PlayerMatchStats{
+			Units:       make(map[string]*UnitTypeStats),
+			CardsPlayed: make(map[string]int),
+		}
+		m.Players[playerID] = p
+	}
+	return p
+}
+
+// unitTypeLocked retorna (creando si hace falta) los contadores de un tipo para un jugador
+func (m *MatchStats) unitTypeLocked(playerID int, unitType string) *UnitTypeStats {
+	p := m.playerLocked(playerID)
+	u, ok := p.Units[unitType]
+	if !ok {
+		u = &UnitTypeStats{}
+		p.Units[unitType] = u
+	}
+	return u
+}
+
+func (m *MatchStats) recordSpawn(unit *UnitState) {
+	m.unitTypeLocked(unit.PlayerID, unit.UnitType).Produced++
+}
+
+// recordGenerated suma una unidad producida por un generador
+func (m *MatchStats) recordGenerated(generator *UnitState) {
+	m.unitTypeLocked(generator.PlayerID, generator.UnitType).Generated++
+}
+
+// recordDamage registra daño recibido por victim y, si la fuente es de un jugador enemigo,
+// daño infligido por el tipo source (unidad o hechizo). playerID 0 = sin fuente (fatiga,
+// muerte súbita).
+func (m *MatchStats) recordDamage(playerID int, source string, victim *UnitState, damage int) {
+	if damage <= 0 {
+		return
+	}
+	m.unitTypeLocked(victim.PlayerID, victim.UnitType).DamageTaken += damage
+	if playerID != 0 && playerID != victim.PlayerID {
+		m.unitTypeLocked(playerID, source).DamageDealt += damage
+	}
+}
+
+// recordDeath registra la muerte de una unidad. killer es el último atacante (nil si no se
+// conoce): si es enemigo se le suma la muerte y, si era una estructura, como destruida.
+func (m *MatchStats) recordDeath(unit *UnitState, killer *UnitState) {
+	m.unitTypeLocked(unit.PlayerID, unit.UnitType).Lost++
+	if killer == nil || killer.PlayerID == unit.PlayerID {
+		return
+	}
+	m.unitTypeLocked(killer.PlayerID, killer.UnitType).Kills++
+	if unit.Category == CategoryStructure {
+		m.playerLocked(killer.PlayerID).StructuresDestroyed++
+	}
+}
+
+// recordPromotion suma un ascenso de veteranía
+func (m *MatchStats) recordPromotion(unit *UnitState) {
+	m.unitTypeLocked(unit.PlayerID, unit.UnitType).Promotions++
+}
+
+func (m *MatchStats) recordCardPlayed(playerID int, card string) {
+	m.playerLocked(playerID).CardsPlayed[card]++
+}
+
+// Copy retorna una copia profunda de las estadísticas con los totales por jugador calculados
+func (m *MatchStats) Copy() *MatchStats {
+	out := NewMatchStats()
+	for pid, p := range m.Players {
+		pc := &PlayerMatchStats{
+			Units:               make(map[string]*UnitTypeStats, len(p.Units)),
+			CardsPlayed:         make(map[string]int, len(p.CardsPlayed)),
+			StructuresDestroyed: p.StructuresDestroyed,
+		}
+		for unitType, u := range p.Units {
+			uc := *u
+			pc.Units[unitType] = &uc
+			pc.DamageDealt += u.DamageDealt
+			pc.DamageTaken += u.DamageTaken
+			pc.UnitsProduced += u.Produced
+			pc.UnitsLost += u.Lost
+			pc.GeneratorOutput += u.Generated
+			pc.Kills += u.Kills
+			pc.Promotions += u.Promotions
+		}
+		for card, n := range p.CardsPlayed {
+			pc.CardsPlayed[card] = n
+		}
+		out.Players[pid] = pc
+	}
+	return out
+}
+
+// GetMatchStats retorna una copia de las estadísticas actuales de la partida
+func (g *GameState) GetMatchStats() *MatchStats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.Stats.Copy()
+}
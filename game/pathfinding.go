@@ -256,9 +256,10 @@ func (pf *PathFinder) reconstructPath(node *PathNode, startX, startY, endX, endY
 		node = node.Parent
 	}
 
-	// Cachear el resultado
-	if len(path) > 0 {
-		pf.cache.Set(startX, startY, endX, endY, path)
+	// Cachear el resultado y cada tramo restante: al avanzar por el camino la unidad sigue la
+	// misma ruta en vez de recalcular una nueva (que con obstáculos móviles puede hacerla oscilar)
+	for i := 0; i < len(path)-1; i++ {
+		pf.cache.Set(path[i].X, path[i].Y, endX, endY, path[i:])
 	}
 
 	return path
@@ -0,0 +1,160 @@
+package game
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// PauseState describe la pausa de la partida. Mientras Paused, ProcessTick no avanza el tick,
+// así que la simulación y los temporizadores de fase quedan congelados.
+type PauseState struct {
+	Paused      bool `json:"paused"`
+	PausedBy    int  `json:"pausedBy,omitempty"`    // Jugador al que se le descuenta la pausa
+	RequestedBy int  `json:"requestedBy,omitempty"` // PvP: pausa pedida, pendiente de que el rival acepte
+	ElapsedMs   int  `json:"elapsedMs"`             // Tiempo real transcurrido en la pausa actual
+	MaxMs       int  `json:"maxMs"`                 // Al llegar se reanuda sola
+}
+
+// Motivos de un evento "game_resumed"
+const (
+	ResumeReasonPlayer  = "player"  // Un jugador la reanudó con resume
+	ResumeReasonTimeout = "timeout" // Se agotó MaxPauseSeconds
+)
+
+// PauseEventData es el payload de los eventos "pause_requested", "game_paused" y "game_resumed"
+type PauseEventData struct {
+	PlayerID int    `json:"playerId"`
+	Reason   string `json:"reason,omitempty"` // Solo game_resumed
+}
+
+// IsPaused indica si la partida está en pausa
+func (g *GameState) IsPaused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.Pause != nil && g.Pause.Paused
+}
+
+// humanPlayersLocked cuenta los jugadores no controlados por la IA (requiere lock tomado)
+func (g *GameState) humanPlayersLocked() int {
+	n := 0
+	for _, p := range g.Players {
+		if !p.IsAI {
+			n++
+		}
+	}
+	return n
+}
+
+// startPauseLocked pausa la partida y descuenta la pausa a playerID (requiere lock tomado)
+func (g *GameState) startPauseLocked(playerID int) {
+	g.Players[playerID].PausesUsed++
+	g.Pause = &PauseState{
+		Paused:   true,
+		PausedBy: playerID,
+		MaxMs:    g.Config.MaxPauseSeconds * 1000,
+	}
+	g.emitEventLocked(EventGamePaused, PauseEventData{PlayerID: playerID})
+	slog.Info("Game paused", "tick", g.Tick, "playerId", playerID)
+}
+
+// resumeLocked reanuda la partida (requiere lock tomado)
+func (g *GameState) resumeLocked(playerID int, reason string) {
+	g.Pause = nil
+	g.emitEventLocked(EventGameResumed, PauseEventData{PlayerID: playerID, Reason: reason})
+	slog.Info("Game resumed", "tick", g.Tick, "playerId", playerID, "reason", reason)
+}
+
+// RequestPause pausa la partida. Contra la IA es inmediato; en PvP la primera llamada deja la
+// pausa pedida y se aplica cuando otro jugador humano también envía pause. Cada jugador tiene
+// MaxPauses pausas (se le descuenta a quien la pidió).
+func (g *GameState) RequestPause(playerID int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, ok := g.Players[playerID]
+	if !ok {
+		return errors.New("player not found")
+	}
+	if p.IsAI {
+		return errors.New("ai players cannot pause")
+	}
+	if g.Pause != nil && g.Pause.Paused {
+		return errors.New("game already paused")
+	}
+
+	// Aceptar la pausa pedida por el rival
+	if g.Pause != nil && g.Pause.RequestedBy != 0 {
+		if g.Pause.RequestedBy == playerID {
+			return errors.New("pause already requested")
+		}
+		g.startPauseLocked(g.Pause.RequestedBy)
+		return nil
+	}
+
+	if p.PausesUsed >= g.Config.MaxPauses {
+		return errors.New("no pauses left")
+	}
+	if g.humanPlayersLocked() <= 1 {
+		g.startPauseLocked(playerID)
+		return nil
+	}
+	g.Pause = &PauseState{RequestedBy: playerID}
+	g.emitEventLocked(EventPauseRequested, PauseEventData{PlayerID: playerID})
+	slog.Info("Pause requested", "tick", g.Tick, "playerId", playerID)
+	return nil
+}
+
+// Resume reanuda la partida en pausa (cualquier jugador humano) o cancela la pausa pedida
+// por el propio jugador
+func (g *GameState) Resume(playerID int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, ok := g.Players[playerID]
+	if !ok || p.IsAI {
+		return errors.New("player not found")
+	}
+	if g.Pause == nil {
+		return errors.New("game not paused")
+	}
+	if !g.Pause.Paused {
+		if g.Pause.RequestedBy != playerID {
+			return errors.New("game not paused")
+		}
+		g.Pause = nil // Cancelar la pausa pedida
+		return nil
+	}
+	g.resumeLocked(playerID, ResumeReasonPlayer)
+	return nil
+}
+
+// advancePauseClock suma un tick de reloj real a la pausa y la reanuda si superó MaxMs
+func (g *GameState) advancePauseClock() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Pause == nil || !g.Pause.Paused {
+		return
+	}
+	g.Pause.ElapsedMs += g.tickMsLocked()
+	if g.Pause.ElapsedMs >= g.Pause.MaxMs {
+		g.resumeLocked(g.Pause.PausedBy, ResumeReasonTimeout)
+	}
+}
+
+// Surrender deja el fin de juego pendiente con el jugador como perdedor (motivo "surrender");
+// se cierra con confirm_end como cualquier otro fin de juego
+func (g *GameState) Surrender(playerID int) error {
+	g.mu.Lock()
+	p, ok := g.Players[playerID]
+	if !ok || p.IsAI {
+		g.mu.Unlock()
+		return errors.New("player not found")
+	}
+	g.Pause = nil
+	g.mu.Unlock()
+
+	g.SetPendingEnd(playerID, "surrender")
+	slog.Info("Player surrendered", "tick", g.Tick, "playerId", playerID)
+	return nil
+}
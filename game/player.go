@@ -3,9 +3,17 @@ package game
 type Player struct {
 	ID        int      `json:"id"`
 	IsAI      bool     `json:"isAi"`
+	Team      int      `json:"team"`      // Equipo del jugador (los aliados no se atacan)
+	BaseID    int      `json:"baseId"`    // Base principal colocada (0 = aún no la colocó)
 	Deck      []string `json:"-"`         // Oculto en JSON
+	DeckList  []string `json:"-"`         // Mazo elegido por el jugador (nil = defaultDeck); se usa al rebarajar
 	Hand      []string `json:"hand"`      // Mano visible para cliente (debug)
 	DeckCount int      `json:"deckCount"` // Tamaño del mazo restante (para UI)
 	Connected bool     `json:"connected"` // Estado de conexión del jugador
 	Ready     bool     `json:"ready"`     // Estado de ready para UI
+
+	MulligansUsed int `json:"mulligansUsed"` // Mulligans hechos en base_selection
+	Fatigue       int `json:"fatigue"`       // Robos con el mazo vacío (solo con FiniteDeck)
+	TimeBankMs    int `json:"timeBankMs"`    // Tiempo restante del banco (solo con TimeBankMs)
+	PausesUsed    int `json:"pausesUsed"`    // Pausas usadas (ver MaxPauses)
 }
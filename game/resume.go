@@ -0,0 +1,102 @@
+package game
+
+// eventLogSize es la cantidad de eventos que guarda cada partida para reenviar al reconectar
+const eventLogSize = 1024
+
+// ResumeMessage se envía a un cliente que reconecta indicando el último seq que recibió: el
+// estado completo actual y los eventos que se perdió. Complete es false si algunos ya no
+// estaban en el buffer (el estado igual está al día, pero faltan esos eventos).
+type ResumeMessage struct {
+	Type     string        `json:"type"` // "resume"
+	Tick     int           `json:"tick"`
+	LastSeq  int           `json:"lastSeq"` // Último seq emitido por la partida
+	Complete bool          `json:"complete"`
+	State    UpdateMessage `json:"state"`
+	Events   []GameEvent   `json:"events"`
+}
+
+// GameEndedMessage avisa a los clientes que la partida terminó y se cerró en el servidor,
+// con el resultado y el motivo (p.ej. disconnect_timeout)
+type GameEndedMessage struct {
+	Type   string       `json:"type"` // "game_ended"
+	GameID int          `json:"gameId"`
+	Result *GameEndInfo `json:"result"`
+}
+
+// recordEventLocked agrega un evento al buffer de reenvío, descartando los más viejos
+// (requiere lock tomado)
+func (g *GameState) recordEventLocked(e GameEvent) {
+	if len(g.eventLog) >= eventLogSize {
+		g.eventLog = append(g.eventLog[:0], g.eventLog[len(g.eventLog)-eventLogSize+1:]...)
+	}
+	g.eventLog = append(g.eventLog, e)
+}
+
+// EventsSince retorna los eventos con seq > lastSeq que puede ver el jugador (públicos y
+// privados suyos). complete es false si el buffer ya descartó alguno posterior a lastSeq.
+func (g *GameState) EventsSince(playerID, lastSeq int) (events []GameEvent, complete bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.eventsSinceLocked(playerID, lastSeq)
+}
+
+// eventsSinceLocked es EventsSince con el lock tomado
+func (g *GameState) eventsSinceLocked(playerID, lastSeq int) (events []GameEvent, complete bool) {
+	complete = len(g.eventLog) == 0 || g.eventLog[0].Seq <= lastSeq+1 || lastSeq >= g.lastEventSeq
+	for _, e := range g.eventLog {
+		if e.Seq <= lastSeq || (e.OwnerID > 0 && e.OwnerID != playerID) {
+			continue
+		}
+		events = append(events, e)
+	}
+	return events, complete
+}
+
+// LastEventSeq retorna el seq del último evento emitido por la partida
+func (g *GameState) LastEventSeq() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.lastEventSeq
+}
+
+// BuildResumeMessage arma el mensaje de reanudación para un jugador que ya recibió hasta lastSeq.
+// Estado, eventos y último seq se leen juntos para que ningún tick quede entre ellos.
+func BuildResumeMessage(state *GameState, playerID, lastSeq int) ResumeMessage {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	return buildResumeMessageLocked(state, playerID, lastSeq)
+}
+
+// ResumeSession arma el mensaje de reanudación y se lo pasa a attach sin soltar el lock del
+// estado. Los eventos se drenan con ese mismo lock, así que si attach encola el mensaje y da de
+// alta al cliente, todo evento drenado después le llega detrás de la reanudación.
+func (g *GameState) ResumeSession(playerID, lastSeq int, attach func(ResumeMessage)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	attach(buildResumeMessageLocked(g, playerID, lastSeq))
+}
+
+func buildResumeMessageLocked(state *GameState, playerID, lastSeq int) ResumeMessage {
+	snapshot := buildSnapshotLocked(state)
+	events, complete := state.eventsSinceLocked(playerID, lastSeq)
+	if events == nil {
+		events = []GameEvent{}
+	}
+	return ResumeMessage{
+		Type:     "resume",
+		Tick:     snapshot.Tick,
+		LastSeq:  state.lastEventSeq,
+		Complete: complete,
+		State:    SnapshotToUpdate(snapshot),
+		Events:   events,
+	}
+}
+
+// BuildGameEndedMessage arma el aviso de partida cerrada
+func BuildGameEndedMessage(gameID int, result *GameEndInfo) GameEndedMessage {
+	return GameEndedMessage{Type: "game_ended", GameID: gameID, Result: result}
+}
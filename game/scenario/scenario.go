@@ -0,0 +1,316 @@
+// Package scenario arma partidas controladas para probar la simulación:
+// se define el mapa, se colocan unidades en tiles concretos, se fuerza una fase,
+// se avanzan N ticks y se verifican posiciones, HP, muertes, ganador o comandos rechazados.
+//
+//	s := scenario.New(t, scenario.WithMap(
+//		"..........",
+//		"..........",
+//	))
+//	tower := s.Unit(s.Human, game.TypeTower, 1, 0)
+//	enemy := s.Unit(s.AI, game.TypeLandSoldier, 8, 0)
+//	s.Phase(game.PhaseBattle)
+//	s.Advance(60)
+//	s.ExpectDead(enemy)
+//	s.ExpectAlive(tower)
+package scenario
+
+import (
+	"encoding/json"
+	"testing"
+
+	"autobattle-server/command"
+	"autobattle-server/game"
+)
+
+// Scenario envuelve un juego en proceso y lo avanza tick a tick sin red
+type Scenario struct {
+	t        testing.TB
+	Game     *game.Game
+	Human    int // ID del jugador humano
+	AI       int // ID del jugador IA
+	Rejected []game.RejectedCommand
+	Events   []game.GameEvent
+}
+
+type options struct {
+	seed   int64
+	config game.PhaseConfig
+	rows   []string
+}
+
+// Option personaliza la creación del escenario
+type Option func(*options)
+
+// WithSeed fija la seed del juego (azar de mazos e IA)
+func WithSeed(seed int64) Option {
+	return func(o *options) { o.seed = seed }
+}
+
+// WithConfig usa una configuración de fases propia
+func WithConfig(config game.PhaseConfig) Option {
+	return func(o *options) { o.config = config }
+}
+
+// WithMap reemplaza el mapa generado por uno descrito en texto ('.' pasto, '=' camino, '~' agua)
+func WithMap(rows ...string) Option {
+	return func(o *options) { o.rows = rows }
+}
+
+// New crea un escenario con un jugador humano y uno IA, ambos con la mano vacía.
+// Por defecto usa un mapa de pasto de 20x10 y la configuración por defecto.
+func New(t testing.TB, opts ...Option) *Scenario {
+	t.Helper()
+
+	o := options{seed: 1, config: game.DefaultPhaseConfig()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.rows == nil {
+		o.rows = make([]string, 10)
+		for i := range o.rows {
+			o.rows[i] = "...................."
+		}
+	}
+
+	g := game.NewGameWithSeed(1, o.seed, o.config)
+	gameMap, err := game.NewGameMapFromRows(o.rows)
+	if err != nil {
+		t.Fatalf("scenario: invalid map: %v", err)
+	}
+	g.State.Map = gameMap
+
+	human := g.State.AddPlayer()
+	s := &Scenario{
+		t:     t,
+		Game:  g,
+		Human: human.ID,
+		AI:    g.State.AIPlayerID,
+	}
+	// Manos vacías: cada prueba reparte explícitamente las cartas que necesita
+	s.Hand(s.Human)
+	s.Hand(s.AI)
+	g.State.DrainHandUpdates()
+	return s
+}
+
+// Base coloca la base principal de un jugador y la registra como tal
+func (s *Scenario) Base(playerID, x, y int) *game.UnitState {
+	s.t.Helper()
+	base := s.Unit(playerID, game.TypeMainBase, x, y)
+	s.Game.State.MarkBasePlaced(playerID, base.ID)
+	return base
+}
+
+// Unit coloca una unidad en el tile indicado (sin exigir área controlada)
+func (s *Scenario) Unit(playerID int, unitType string, x, y int) *game.UnitState {
+	s.t.Helper()
+	unit := s.Game.State.PlaceUnit(playerID, unitType, x, y)
+	if unit == nil {
+		s.t.Fatalf("scenario: cannot place %s for player %d at (%d,%d)", unitType, playerID, x, y)
+	}
+	return unit
+}
+
+// Hand reemplaza la mano de un jugador
+func (s *Scenario) Hand(playerID int, cards ...string) {
+	s.t.Helper()
+	p, ok := s.Game.State.Players[playerID]
+	if !ok {
+		s.t.Fatalf("scenario: unknown player %d", playerID)
+	}
+	p.Hand = append([]string{}, cards...)
+}
+
+// Phase fuerza la fase actual del juego
+func (s *Scenario) Phase(phase game.GamePhase) {
+	s.Game.State.ForcePhase(phase)
+}
+
+// Command encola un comando; el payload pasa por JSON igual que al llegar por HTTP
+func (s *Scenario) Command(playerID int, cmdType command.CommandType, data any) {
+	s.t.Helper()
+	var decoded any
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			s.t.Fatalf("scenario: cannot encode command data: %v", err)
+		}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			s.t.Fatalf("scenario: cannot decode command data: %v", err)
+		}
+	}
+	s.Game.Commands.Enqueue(command.Command{
+		GameID:   s.Game.ID,
+		PlayerID: playerID,
+		Type:     cmdType,
+		Data:     decoded,
+	})
+}
+
+// Advance ejecuta n ticks del loop del servidor. Con el fin de juego pendiente (o en pausa)
+// la simulación no avanza: solo se aplican los comandos permitidos (confirm_end, resume...).
+func (s *Scenario) Advance(n int) {
+	for i := 0; i < n; i++ {
+		s.Game.Simulation.ProcessTick()
+		s.Rejected = append(s.Rejected, s.Game.Simulation.DrainRejectedCommands()...)
+		s.Events = append(s.Events, s.Game.State.DrainEvents()...)
+		if s.Game.State.IsGameEndPending() {
+			continue
+		}
+		if end := s.Game.Simulation.CheckGameEnd(); end != nil {
+			s.Game.State.SetPendingEndInfo(end)
+		}
+	}
+}
+
+// AdvanceUntil avanza hasta que cond se cumpla o se agoten maxTicks; retorna si se cumplió
+func (s *Scenario) AdvanceUntil(maxTicks int, cond func() bool) bool {
+	for i := 0; i < maxTicks; i++ {
+		if cond() {
+			return true
+		}
+		s.Advance(1)
+	}
+	return cond()
+}
+
+// Units retorna las unidades vivas de un jugador de un tipo dado
+func (s *Scenario) Units(playerID int, unitType string) []*game.UnitState {
+	var out []*game.UnitState
+	for _, u := range s.Game.State.Units {
+		if u.PlayerID == playerID && u.UnitType == unitType {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// EventsOfType retorna los eventos emitidos de un tipo dado
+func (s *Scenario) EventsOfType(eventType game.EventType) []game.GameEvent {
+	var out []game.GameEvent
+	for _, e := range s.Events {
+		if e.Type == eventType {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// =======================
+// Verificaciones
+// =======================
+
+func (s *Scenario) alive(unit *game.UnitState) bool {
+	current, ok := s.Game.State.Units[unit.ID]
+	return ok && current.HP > 0
+}
+
+// ExpectAt verifica la posición de una unidad
+func (s *Scenario) ExpectAt(unit *game.UnitState, x, y int) {
+	s.t.Helper()
+	if unit.X != x || unit.Y != y {
+		s.t.Errorf("unit %d (%s): expected at (%d,%d), got (%d,%d)", unit.ID, unit.UnitType, x, y, unit.X, unit.Y)
+	}
+}
+
+// ExpectHP verifica el HP exacto de una unidad
+func (s *Scenario) ExpectHP(unit *game.UnitState, hp int) {
+	s.t.Helper()
+	if unit.HP != hp {
+		s.t.Errorf("unit %d (%s): expected hp %d, got %d", unit.ID, unit.UnitType, hp, unit.HP)
+	}
+}
+
+// ExpectHPBelow verifica que una unidad haya recibido daño por debajo de un umbral
+func (s *Scenario) ExpectHPBelow(unit *game.UnitState, hp int) {
+	s.t.Helper()
+	if unit.HP >= hp {
+		s.t.Errorf("unit %d (%s): expected hp below %d, got %d", unit.ID, unit.UnitType, hp, unit.HP)
+	}
+}
+
+// ExpectDead verifica que la unidad fue eliminada
+func (s *Scenario) ExpectDead(unit *game.UnitState) {
+	s.t.Helper()
+	if s.alive(unit) {
+		s.t.Errorf("unit %d (%s): expected dead, has %d hp", unit.ID, unit.UnitType, unit.HP)
+	}
+}
+
+// ExpectAlive verifica que la unidad sigue en juego
+func (s *Scenario) ExpectAlive(unit *game.UnitState) {
+	s.t.Helper()
+	if !s.alive(unit) {
+		s.t.Errorf("unit %d (%s): expected alive", unit.ID, unit.UnitType)
+	}
+}
+
+// ExpectUnitCount verifica cuántas unidades vivas de un tipo tiene un jugador
+func (s *Scenario) ExpectUnitCount(playerID int, unitType string, n int) {
+	s.t.Helper()
+	if got := len(s.Units(playerID, unitType)); got != n {
+		s.t.Errorf("player %d: expected %d %s, got %d", playerID, n, unitType, got)
+	}
+}
+
+// ExpectHandSize verifica el tamaño de la mano de un jugador
+func (s *Scenario) ExpectHandSize(playerID int, n int) {
+	s.t.Helper()
+	if got := len(s.Game.State.Players[playerID].Hand); got != n {
+		s.t.Errorf("player %d: expected %d cards in hand, got %d", playerID, n, got)
+	}
+}
+
+// ExpectWinner verifica que la partida terminó con el ganador indicado
+func (s *Scenario) ExpectWinner(playerID int) {
+	s.t.Helper()
+	end := s.Game.State.GameEnd
+	if end == nil || !end.Pending {
+		s.t.Errorf("expected winner %d, game has not ended", playerID)
+		return
+	}
+	if end.WinnerID != playerID {
+		s.t.Errorf("expected winner %d, got %d (reason %s)", playerID, end.WinnerID, end.Reason)
+	}
+}
+
+// ExpectDraw verifica que la partida terminó en empate
+func (s *Scenario) ExpectDraw() {
+	s.t.Helper()
+	end := s.Game.State.GameEnd
+	if end == nil || !end.Pending {
+		s.t.Errorf("expected a draw, game has not ended")
+		return
+	}
+	if !end.Draw || end.WinnerID != 0 {
+		s.t.Errorf("expected a draw, got winner %d (reason %s)", end.WinnerID, end.Reason)
+	}
+}
+
+// ExpectNoWinner verifica que la partida sigue en curso
+func (s *Scenario) ExpectNoWinner() {
+	s.t.Helper()
+	if end := s.Game.State.GameEnd; end != nil && end.Pending {
+		s.t.Errorf("expected game in progress, ended with winner %d (reason %s)", end.WinnerID, end.Reason)
+	}
+}
+
+// ExpectRejected verifica que un comando del tipo indicado fue rechazado y retorna el motivo
+func (s *Scenario) ExpectRejected(cmdType command.CommandType) string {
+	s.t.Helper()
+	for _, r := range s.Rejected {
+		if r.Command.Type == cmdType {
+			return r.Reason
+		}
+	}
+	s.t.Errorf("expected a rejected %s command, rejected: %v", cmdType, s.Rejected)
+	return ""
+}
+
+// ExpectNoRejected verifica que ningún comando fue rechazado
+func (s *Scenario) ExpectNoRejected() {
+	s.t.Helper()
+	if len(s.Rejected) > 0 {
+		s.t.Errorf("expected no rejected commands, got %v", s.Rejected)
+	}
+}
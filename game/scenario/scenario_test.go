@@ -0,0 +1,1588 @@
+package scenario
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"autobattle-server/command"
+	"autobattle-server/game"
+)
+
+func TestMain(m *testing.M) {
+	// La simulación registra cada tick con slog; en las pruebas solo interesa el resultado
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+func spawn(unitType string, x, y int) map[string]any {
+	return map[string]any{"unitType": unitType, "x": x, "y": y}
+}
+
+func TestCombatTowerKillsApproachingSoldier(t *testing.T) {
+	s := New(t)
+	tower := s.Unit(s.Human, game.TypeTower, 2, 5)
+	soldier := s.Unit(s.AI, game.TypeLandSoldier, 12, 5)
+
+	s.Phase(game.PhaseBattle)
+	s.Advance(60)
+
+	s.ExpectDead(soldier)
+	s.ExpectAlive(tower)
+}
+
+func TestCombatSoldiersTradeDamageInMelee(t *testing.T) {
+	s := New(t)
+	ours := s.Unit(s.Human, game.TypeLandSoldier, 5, 5)
+	theirs := s.Unit(s.AI, game.TypeLandSoldier, 6, 5)
+
+	s.Phase(game.PhaseBattle)
+	s.Advance(10)
+
+	s.ExpectHPBelow(ours, 100)
+	s.ExpectHPBelow(theirs, 100)
+	s.ExpectAt(ours, 5, 5)
+	s.ExpectAt(theirs, 6, 5)
+}
+
+func TestDamageMatrixScalesHitsByArmorClass(t *testing.T) {
+	s := New(t)
+	soldier := s.Unit(s.Human, game.TypeLandSoldier, 5, 5)
+	generator := s.Unit(s.AI, game.TypeLandGenerator, 6, 5)
+
+	s.Phase(game.PhaseBattle)
+	s.AdvanceUntil(20, func() bool { return generator.HP < 300 })
+
+	// melee (15) contra fortified: 15 × 0.75 = 11.25 → 11
+	s.ExpectHP(generator, 289)
+	s.ExpectHP(soldier, 100)
+}
+
+func TestDamageMatrixFromConfig(t *testing.T) {
+	config := game.DefaultPhaseConfig()
+	config.DamageMatrix = game.DamageMatrix{
+		game.DamageMelee: {game.ArmorFortified: 2.0},
+	}
+	s := New(t, WithConfig(config))
+	s.Unit(s.Human, game.TypeLandSoldier, 5, 5)
+	generator := s.Unit(s.AI, game.TypeLandGenerator, 6, 5)
+
+	s.Phase(game.PhaseBattle)
+	s.AdvanceUntil(20, func() bool { return generator.HP < 300 })
+
+	s.ExpectHP(generator, 270)
+}
+
+// coastMap: agua en las dos primeras columnas y tierra al este
+var coastMap = []string{
+	"~~..................",
+	"~~..................",
+	"~~..................",
+	"~~..................",
+	"~~..................",
+	"~~..................",
+	"~~..................",
+	"~~..................",
+}
+
+func attacksBy(s *Scenario, attackerID int) []game.AttackEventData {
+	var out []game.AttackEventData
+	for _, e := range s.EventsOfType(game.EventAttack) {
+		if data := e.Data.(game.AttackEventData); data.AttackerID == attackerID {
+			out = append(out, data)
+		}
+	}
+	return out
+}
+
+func firstAttack(t *testing.T, s *Scenario, attackerID int) game.AttackEventData {
+	t.Helper()
+	s.AdvanceUntil(20, func() bool { return len(attacksBy(s, attackerID)) > 0 })
+	attacks := attacksBy(s, attackerID)
+	if len(attacks) == 0 {
+		t.Fatalf("no attack event from unit %d", attackerID)
+	}
+	return attacks[0]
+}
+
+func TestNavalBombardmentSplashesNeighbours(t *testing.T) {
+	s := New(t, WithMap(coastMap...))
+	ship := s.Unit(s.Human, game.TypeNavalShip, 0, 4)
+	// Fuera del DetectionRange (10) de los soldados, dentro del AttackRange (15) del barco
+	near := s.Unit(s.AI, game.TypeLandSoldier, 12, 4)
+	neighbour := s.Unit(s.AI, game.TypeLandSoldier, 12, 5)
+	far := s.Unit(s.AI, game.TypeLandSoldier, 12, 7)
+
+	s.Phase(game.PhaseBattle)
+	attack := firstAttack(t, s, ship.ID)
+
+	// naval (20) contra light ×1.0; vecino a 1 tile con falloff lineal de radio 1 → ×0.5
+	s.ExpectHP(near, 80)
+	s.ExpectHP(neighbour, 90)
+	s.ExpectHP(far, 100)
+	if attack.TargetID != near.ID || len(attack.Hits) != 2 {
+		t.Errorf("expected 2 hits centered on unit %d, got %+v", near.ID, attack)
+	}
+}
+
+func TestSplashFriendlyFireIsPerGameOption(t *testing.T) {
+	for _, friendlyFire := range []bool{false, true} {
+		config := game.DefaultPhaseConfig()
+		config.FriendlyFire = friendlyFire
+		s := New(t, WithMap(coastMap...), WithConfig(config))
+		ship := s.Unit(s.Human, game.TypeNavalShip, 0, 4)
+		s.Unit(s.AI, game.TypeLandSoldier, 12, 4)
+		ally := s.Unit(s.Human, game.TypeWall, 12, 5)
+		ally.IsTargetable = true // Blanco inmóvil que no ataca
+
+		s.Phase(game.PhaseBattle)
+		hitAlly := false
+		for _, hit := range firstAttack(t, s, ship.ID).Hits {
+			if hit.UnitID == ally.ID {
+				hitAlly = hit.Friendly
+			}
+		}
+		if hitAlly != friendlyFire {
+			t.Errorf("friendlyFire=%v: ally hit=%v", friendlyFire, hitAlly)
+		}
+	}
+}
+
+func TestStatusEffects(t *testing.T) {
+	t.Run("stun blocks attacks until it expires", func(t *testing.T) {
+		s := New(t)
+		s.Unit(s.Human, game.TypeLandSoldier, 5, 5)
+		generator := s.Unit(s.AI, game.TypeLandGenerator, 6, 5)
+		ours := s.Units(s.Human, game.TypeLandSoldier)[0]
+		s.Game.State.ApplyStatusEffect(ours.ID, game.StatusEffect{Kind: game.EffectStun, RemainingTicks: 30})
+
+		s.Phase(game.PhaseBattle)
+		s.Advance(29)
+		s.ExpectHP(generator, 300)
+		if ours.Status != "stunned" {
+			t.Errorf("expected stunned status, got %q", ours.Status)
+		}
+		s.Advance(10)
+		s.ExpectHPBelow(generator, 300)
+	})
+
+	t.Run("slow stacks and scales move interval", func(t *testing.T) {
+		s := New(t)
+		soldier := s.Unit(s.Human, game.TypeLandSoldier, 0, 0)
+		slow := game.StatusEffect{Kind: game.EffectSlow, RemainingTicks: 100, Magnitude: 0.5}
+		s.Game.State.ApplyStatusEffect(soldier.ID, slow)
+		s.Game.State.ApplyStatusEffect(soldier.ID, slow)
+		if len(soldier.Effects) != 1 || soldier.Effects[0].Stacks != 2 {
+			t.Fatalf("expected one slow with 2 stacks, got %+v", soldier.Effects)
+		}
+		s.Game.State.SetUnitDestination(s.Human, soldier.ID, 19, 0)
+
+		// Primer paso en el tick 5 (intervalo inicial), luego cada 5 × (1 + 0.5×2) = 10 ticks
+		s.Phase(game.PhaseBattle)
+		s.Advance(25)
+		s.ExpectAt(soldier, 3, 0)
+	})
+
+	t.Run("burn deals damage over time through shields", func(t *testing.T) {
+		s := New(t)
+		wall := s.Unit(s.Human, game.TypeWall, 5, 5)
+		s.Game.State.ApplyStatusEffect(wall.ID, game.StatusEffect{Kind: game.EffectShield, RemainingTicks: 100, Magnitude: 10})
+		s.Game.State.ApplyStatusEffect(wall.ID, game.StatusEffect{Kind: game.EffectBurn, RemainingTicks: 20, Magnitude: 6})
+
+		s.Phase(game.PhaseBattle)
+		s.Advance(30)
+
+		// 4 pulsos de 6 = 24; el escudo absorbe 10 y luego desaparece
+		s.ExpectHP(wall, 186)
+		if len(wall.Effects) != 0 {
+			t.Errorf("expected all effects expired, got %+v", wall.Effects)
+		}
+	})
+
+	t.Run("shield absorbs burn once after an earlier effect expires", func(t *testing.T) {
+		s := New(t)
+		wall := s.Unit(s.Human, game.TypeWall, 5, 5)
+		s.Game.State.ApplyStatusEffect(wall.ID, game.StatusEffect{Kind: game.EffectReveal, RemainingTicks: 5})
+		s.Game.State.ApplyStatusEffect(wall.ID, game.StatusEffect{Kind: game.EffectShield, RemainingTicks: 100, Magnitude: 10})
+		s.Game.State.ApplyStatusEffect(wall.ID, game.StatusEffect{Kind: game.EffectBurn, RemainingTicks: 20, Magnitude: 12})
+		s.Game.State.ApplyStatusEffect(wall.ID, game.StatusEffect{Kind: game.EffectBurn, RemainingTicks: 20, Magnitude: 12})
+
+		s.Phase(game.PhaseBattle)
+		s.Advance(30)
+
+		// El reveal vence en el tick del primer pulso. 2 burns × 4 pulsos de 12 = 96; el escudo absorbe
+		// 10 una sola vez
+		s.ExpectHP(wall, 114)
+		if len(wall.Effects) != 0 {
+			t.Errorf("expected all effects expired, got %+v", wall.Effects)
+		}
+	})
+}
+
+func TestTargetPriority(t *testing.T) {
+	t.Run("tower finishes off the weakest enemy", func(t *testing.T) {
+		s := New(t)
+		tower := s.Unit(s.Human, game.TypeTower, 2, 5)
+		s.Unit(s.AI, game.TypeLandSoldier, 14, 5)
+		wounded := s.Unit(s.AI, game.TypeLandSoldier, 16, 5)
+		wounded.HP = 30
+
+		s.Phase(game.PhaseBattle)
+		if attack := firstAttack(t, s, tower.ID); attack.TargetID != wounded.ID {
+			t.Errorf("expected tower to shoot wounded unit %d, shot %d", wounded.ID, attack.TargetID)
+		}
+	})
+
+	t.Run("ship bombards structures first", func(t *testing.T) {
+		s := New(t, WithMap(coastMap...))
+		ship := s.Unit(s.Human, game.TypeNavalShip, 0, 4)
+		s.Unit(s.AI, game.TypeLandSoldier, 12, 4)
+		generator := s.Unit(s.AI, game.TypeLandGenerator, 12, 1)
+
+		s.Phase(game.PhaseBattle)
+		if attack := firstAttack(t, s, ship.ID); attack.TargetID != generator.ID {
+			t.Errorf("expected ship to bombard generator %d, hit %d", generator.ID, attack.TargetID)
+		}
+	})
+}
+
+func TestFocusTarget(t *testing.T) {
+	s := New(t)
+	tower := s.Unit(s.Human, game.TypeTower, 2, 5)
+	s.Unit(s.AI, game.TypeLandSoldier, 14, 5)
+	s.Unit(s.AI, game.TypeLandSoldier, 15, 5).HP = 30
+	focus := s.Unit(s.AI, game.TypeLandSoldier, 16, 5)
+	scout := s.Unit(s.Human, game.TypeLandSoldier, 0, 0)
+
+	s.Phase(game.PhasePreparation)
+	s.Command(s.Human, command.CommandFocusTarget, map[string]any{"unitId": tower.ID, "targetId": focus.ID})
+	s.Command(s.AI, command.CommandFocusTarget, map[string]any{"unitId": tower.ID, "targetId": focus.ID})
+	s.Command(s.Human, command.CommandFocusTarget, map[string]any{"unitId": scout.ID, "targetId": focus.ID})
+	s.Advance(1)
+
+	if reason := s.ExpectRejected(command.CommandFocusTarget); reason != "unit not owned by player" {
+		t.Errorf("unexpected rejection reason %q", reason)
+	}
+	if len(s.Rejected) != 2 || s.Rejected[1].Reason != "target out of range" {
+		t.Errorf("expected out of range rejection, got %v", s.Rejected)
+	}
+	if tower.FocusTargetID != focus.ID {
+		t.Fatalf("expected focus on %d, got %d", focus.ID, tower.FocusTargetID)
+	}
+
+	s.Phase(game.PhaseBattle)
+	if attack := firstAttack(t, s, tower.ID); attack.TargetID != focus.ID {
+		t.Errorf("expected focused unit %d to be shot first, shot %d", focus.ID, attack.TargetID)
+	}
+
+	// Al morir el objetivo fijado, el foco se limpia y vuelve la política del tipo
+	s.AdvanceUntil(200, func() bool { return tower.FocusTargetID == 0 })
+	s.ExpectDead(focus)
+}
+
+func TestMoveGroupKeepsFormation(t *testing.T) {
+	s := New(t)
+	a := s.Unit(s.Human, game.TypeLandSoldier, 2, 2)
+	b := s.Unit(s.Human, game.TypeLandSoldier, 2, 3)
+	slow := s.Unit(s.Human, game.TypeLandSoldier, 3, 2)
+	slow.MoveIntervalTicks = 10
+	enemy := s.Unit(s.AI, game.TypeLandSoldier, 19, 9)
+
+	s.Phase(game.PhasePreparation)
+	s.Command(s.Human, command.CommandMoveGroup, map[string]any{"unitIds": []int{a.ID, b.ID, enemy.ID}, "x": 10, "y": 6})
+	s.Command(s.Human, command.CommandMoveGroup, map[string]any{"unitIds": []int{a.ID, b.ID, slow.ID}, "x": 10, "y": 6})
+	s.Advance(1)
+	if reason := s.ExpectRejected(command.CommandMoveGroup); reason != "unit not owned by player" {
+		t.Errorf("unexpected rejection reason %q", reason)
+	}
+	if len(s.Rejected) != 1 {
+		t.Fatalf("expected only the mixed group to be rejected, got %v", s.Rejected)
+	}
+
+	// El grupo marcha al ritmo del más lento (un paso cada 10 ticks)
+	s.Phase(game.PhaseBattle)
+	s.Advance(30)
+	if moved := abs(a.X-2) + abs(a.Y-2); moved > 3 {
+		t.Errorf("expected group pace, fast unit moved %d tiles in 30 ticks", moved)
+	}
+
+	s.Advance(200)
+	s.ExpectAt(a, 10, 6)
+	s.ExpectAt(b, 10, 7)
+	s.ExpectAt(slow, 11, 6)
+}
+
+func TestStances(t *testing.T) {
+	t.Run("hold never moves and persists across turns", func(t *testing.T) {
+		s := New(t)
+		soldier := s.Unit(s.Human, game.TypeLandSoldier, 5, 5)
+		generator := s.Unit(s.AI, game.TypeLandGenerator, 9, 5)
+
+		s.Phase(game.PhasePreparation)
+		s.Command(s.Human, command.CommandSetStance, map[string]any{"unitIds": []int{soldier.ID}, "stance": "hold"})
+		s.Advance(1)
+		s.Command(s.Human, command.CommandMoveUnit, map[string]any{"unitId": soldier.ID, "x": 9, "y": 4})
+		s.Advance(1)
+		s.ExpectRejected(command.CommandMoveUnit)
+
+		s.Phase(game.PhaseBattle)
+		s.Advance(40)
+		s.ExpectAt(soldier, 5, 5)
+		s.ExpectHP(generator, 300)
+
+		s.Phase(game.PhaseTurnEnd)
+		s.Game.State.AdvancePhase()
+		if soldier.Stance != game.StanceHold {
+			t.Errorf("expected hold stance to persist, got %q", soldier.Stance)
+		}
+	})
+
+	t.Run("defensive only engages inside its leash", func(t *testing.T) {
+		s := New(t)
+		soldier := s.Unit(s.Human, game.TypeLandSoldier, 5, 5)
+		far := s.Unit(s.AI, game.TypeLandGenerator, 12, 5)
+		near := s.Unit(s.AI, game.TypeLandGenerator, 5, 8)
+
+		s.Phase(game.PhasePreparation)
+		s.Command(s.Human, command.CommandSetStance, map[string]any{"unitIds": []int{soldier.ID}, "stance": "defensive", "x": 5, "y": 5, "leash": 3})
+		s.Command(s.Human, command.CommandSetStance, map[string]any{"unitIds": []int{soldier.ID}, "stance": "berserk"})
+		s.Advance(1)
+		if reason := s.ExpectRejected(command.CommandSetStance); reason != "invalid stance" {
+			t.Errorf("unexpected rejection reason %q", reason)
+		}
+
+		s.Phase(game.PhaseBattle)
+		s.Advance(60)
+		s.ExpectHPBelow(near, 300)
+		s.ExpectHP(far, 300)
+		if abs(soldier.X-5)+abs(soldier.Y-5) > 3 {
+			t.Errorf("defensive unit left its leash: at (%d,%d)", soldier.X, soldier.Y)
+		}
+	})
+}
+
+// riverMap: un río vertical en x=10 con un único vado en (10,5)
+var riverMap = []string{
+	"..........~.........",
+	"..........~.........",
+	"..........~.........",
+	"..........~.........",
+	"..........~.........",
+	"....................",
+	"..........~.........",
+	"..........~.........",
+	"..........~.........",
+	"..........~.........",
+}
+
+func TestWallLinesConnectAdjacentSegments(t *testing.T) {
+	s := New(t)
+	top := s.Unit(s.Human, game.TypeWall, 5, 3)
+	middle := s.Unit(s.Human, game.TypeWall, 5, 4)
+	bottom := s.Unit(s.Human, game.TypeWall, 5, 5)
+	enemy := s.Unit(s.AI, game.TypeWall, 6, 4)
+	lone := s.Unit(s.Human, game.TypeWall, 9, 9)
+
+	if middle.WallMask != game.WallConnectN|game.WallConnectS || top.WallMask != game.WallConnectS || bottom.WallMask != game.WallConnectN {
+		t.Errorf("unexpected masks top=%d middle=%d bottom=%d", top.WallMask, middle.WallMask, bottom.WallMask)
+	}
+	if top.WallLineID != top.ID || middle.WallLineID != top.ID || bottom.WallLineID != top.ID {
+		t.Errorf("expected one line %d, got %d/%d/%d", top.ID, top.WallLineID, middle.WallLineID, bottom.WallLineID)
+	}
+	if enemy.WallMask != 0 || enemy.WallLineID != enemy.ID || lone.WallLineID != lone.ID {
+		t.Errorf("enemy and lone walls must form their own lines: %+v %+v", enemy, lone)
+	}
+}
+
+func TestSealedPathIsBreached(t *testing.T) {
+	s := New(t, WithMap(riverMap...))
+	wall := s.Unit(s.Human, game.TypeWall, 10, 5)
+	generator := s.Unit(s.Human, game.TypeLandGenerator, 5, 5)
+	generator.GenerationInterval = 100000
+	soldier := s.Unit(s.AI, game.TypeLandSoldier, 14, 5)
+
+	s.Phase(game.PhaseBattle)
+	s.AdvanceUntil(30, func() bool { return soldier.BreachTargetID != 0 })
+	if soldier.BreachTargetID != wall.ID {
+		t.Fatalf("expected soldier to breach wall %d, got %d", wall.ID, soldier.BreachTargetID)
+	}
+
+	s.AdvanceUntil(400, func() bool { return len(s.EventsOfType(game.EventWallBreached)) > 0 })
+	s.ExpectDead(wall)
+	breaches := s.EventsOfType(game.EventWallBreached)
+	if len(breaches) != 1 {
+		t.Fatalf("expected one wall_breached event, got %d", len(breaches))
+	}
+	if data := breaches[0].Data.(game.WallBreachedEventData); data.WallID != wall.ID || data.BreachedByID != soldier.ID {
+		t.Errorf("unexpected breach event %+v", data)
+	}
+
+	s.Advance(60)
+	s.ExpectHPBelow(generator, 300)
+}
+
+func TestOpenPathIsPreferredOverBreach(t *testing.T) {
+	rows := append([]string{}, riverMap...)
+	rows[9] = "...................."
+	s := New(t, WithMap(rows...))
+	wall := s.Unit(s.Human, game.TypeWall, 10, 5)
+	s.Unit(s.Human, game.TypeLandGenerator, 5, 5).GenerationInterval = 100000
+	soldier := s.Unit(s.AI, game.TypeLandSoldier, 14, 5)
+
+	s.Phase(game.PhaseBattle)
+	s.Advance(60)
+
+	s.ExpectHP(wall, 200)
+	if soldier.BreachTargetID != 0 {
+		t.Errorf("expected no breach with an open path, breaching %d", soldier.BreachTargetID)
+	}
+}
+
+func TestClosingTheOpenPathStartsABreach(t *testing.T) {
+	rows := append([]string{}, riverMap...)
+	rows[9] = "...................."
+	s := New(t, WithMap(rows...))
+	s.Unit(s.Human, game.TypeWall, 10, 5)
+	s.Unit(s.Human, game.TypeLandGenerator, 5, 5).GenerationInterval = 100000
+	soldier := s.Unit(s.AI, game.TypeLandSoldier, 14, 5)
+
+	s.Phase(game.PhaseBattle)
+	s.Advance(5)
+	if soldier.BreachTargetID != 0 {
+		t.Fatalf("expected no breach with an open path, breaching %d", soldier.BreachTargetID)
+	}
+
+	// Sellar el paso abierto cambia la disposición de murallas: el camino guardado ya no vale
+	s.Unit(s.Human, game.TypeWall, 10, 9)
+	if !s.AdvanceUntil(5, func() bool { return soldier.BreachTargetID != 0 }) {
+		t.Fatal("expected the soldier to start a breach once the open path was sealed")
+	}
+}
+
+func TestSiegeBonusAgainstWalls(t *testing.T) {
+	s := New(t, WithMap(riverMap...))
+	wall := s.Unit(s.Human, game.TypeWall, 10, 5)
+	s.Unit(s.Human, game.TypeLandGenerator, 5, 5).GenerationInterval = 100000
+	ram := s.Unit(s.AI, game.TypeSiegeRam, 11, 5)
+
+	s.Phase(game.PhaseBattle)
+	firstAttack(t, s, ram.ID)
+	s.AdvanceUntil(40, func() bool { return wall.HP < 200 })
+
+	// siege (30) contra fortified ×2 y bonus contra murallas ×1.5 = 90
+	s.ExpectHP(wall, 110)
+}
+
+func playCard(card string, x, y int) map[string]any {
+	return map[string]any{"card": card, "x": x, "y": y}
+}
+
+func TestSpellCards(t *testing.T) {
+	t.Run("fireball damages enemies in the area", func(t *testing.T) {
+		s := New(t)
+		s.Unit(s.Human, game.TypeTower, 2, 5)
+		center := s.Unit(s.AI, game.TypeLandSoldier, 8, 5)
+		edge := s.Unit(s.AI, game.TypeLandSoldier, 9, 5)
+		ours := s.Unit(s.Human, game.TypeLandSoldier, 8, 6)
+		s.Hand(s.Human, game.SpellFireball, game.SpellFireball)
+
+		s.Phase(game.PhasePreparation)
+		s.Command(s.Human, command.CommandPlayCard, playCard(game.SpellFireball, 8, 5))
+		s.Command(s.Human, command.CommandPlayCard, playCard(game.SpellFireball, 19, 9))
+		s.Advance(1)
+
+		// 60 en el centro; a 1 tile con falloff lineal de radio 2 → 60 × 2/3 = 40
+		s.ExpectHP(center, 40)
+		s.ExpectHP(edge, 60)
+		s.ExpectHP(ours, 100)
+		if reason := s.ExpectRejected(command.CommandPlayCard); reason != "target out of range" {
+			t.Errorf("unexpected rejection reason %q", reason)
+		}
+		s.ExpectHandSize(s.Human, 1)
+
+		casts := s.EventsOfType(game.EventSpellCast)
+		if len(casts) != 1 || len(casts[0].Data.(game.SpellCastEventData).Hits) != 2 {
+			t.Errorf("expected one spell_cast event with 2 hits, got %+v", casts)
+		}
+	})
+
+	t.Run("validated against phase and command", func(t *testing.T) {
+		s := New(t)
+		s.Unit(s.Human, game.TypeTower, 2, 5)
+		s.Hand(s.Human, game.SpellRally, game.SpellFireball)
+
+		s.Phase(game.PhaseTurnStart)
+		s.Command(s.Human, command.CommandPlayCard, map[string]any{"card": game.SpellRally})
+		s.Advance(1)
+		if reason := s.ExpectRejected(command.CommandPlayCard); reason != "card cannot be played in this phase" {
+			t.Errorf("unexpected rejection reason %q", reason)
+		}
+
+		s.Phase(game.PhasePreparation)
+		s.Command(s.Human, command.CommandSpawnUnit, spawn(game.SpellFireball, 4, 5))
+		s.Advance(1)
+		if reason := s.ExpectRejected(command.CommandSpawnUnit); reason != "spell cards must be played with play_card" {
+			t.Errorf("unexpected rejection reason %q", reason)
+		}
+		s.ExpectHandSize(s.Human, 2)
+	})
+
+	t.Run("rally buffs own units and heal wave heals them", func(t *testing.T) {
+		s := New(t)
+		s.Unit(s.Human, game.TypeTower, 2, 5)
+		soldier := s.Unit(s.Human, game.TypeLandSoldier, 5, 5)
+		soldier.HP = 50
+		s.Hand(s.Human, game.SpellRally, game.SpellHealWave)
+
+		s.Phase(game.PhasePreparation)
+		s.Command(s.Human, command.CommandPlayCard, map[string]any{"card": game.SpellRally})
+		s.Command(s.Human, command.CommandPlayCard, playCard(game.SpellHealWave, 6, 5))
+		s.Advance(1)
+
+		s.ExpectNoRejected()
+		s.ExpectHP(soldier, 90)
+		if len(soldier.Effects) != 1 || soldier.Effects[0].Kind != game.EffectRally {
+			t.Errorf("expected rally effect, got %+v", soldier.Effects)
+		}
+	})
+
+	t.Run("reveal exposes a wall to attacks", func(t *testing.T) {
+		s := New(t)
+		tower := s.Unit(s.Human, game.TypeTower, 2, 5)
+		wall := s.Unit(s.AI, game.TypeWall, 8, 5)
+		s.Hand(s.Human, game.SpellReveal)
+
+		s.Phase(game.PhaseBattle)
+		s.Advance(20)
+		s.ExpectHP(wall, 200)
+
+		s.Command(s.Human, command.CommandPlayCard, map[string]any{"card": game.SpellReveal, "unitId": wall.ID})
+		if attack := firstAttack(t, s, tower.ID); attack.TargetID != wall.ID {
+			t.Errorf("expected tower to shoot revealed wall, shot %d", attack.TargetID)
+		}
+		s.ExpectHPBelow(wall, 200)
+	})
+}
+
+func TestSupportUnits(t *testing.T) {
+	t.Run("medic walks into range and heals up to max hp", func(t *testing.T) {
+		s := New(t)
+		medic := s.Unit(s.Human, game.TypeMedic, 0, 5)
+		soldier := s.Unit(s.Human, game.TypeLandSoldier, 6, 5)
+		soldier.HP = 40
+
+		s.Phase(game.PhaseBattle)
+		s.Advance(100)
+
+		s.ExpectHP(soldier, 100)
+		if medic.X+3 < soldier.X {
+			t.Errorf("expected medic within heal range, at (%d,%d)", medic.X, medic.Y)
+		}
+		if medic.HealedTotal != 60 {
+			t.Errorf("expected 60 hp healed, got %d", medic.HealedTotal)
+		}
+		// 6 curaciones de 10, separadas por el cooldown del médico
+		heals := s.EventsOfType(game.EventHeal)
+		if len(heals) != 6 {
+			t.Fatalf("expected 6 heal events, got %d", len(heals))
+		}
+		for i := 1; i < len(heals); i++ {
+			if gap := heals[i].Tick - heals[i-1].Tick; gap < 10 {
+				t.Errorf("heal %d only %d ticks after the previous one", i, gap)
+			}
+		}
+	})
+
+	t.Run("medic ignores structures and enemies", func(t *testing.T) {
+		s := New(t)
+		s.Unit(s.Human, game.TypeMedic, 5, 5)
+		wall := s.Unit(s.Human, game.TypeWall, 6, 5)
+		enemy := s.Unit(s.AI, game.TypeLandSoldier, 5, 7)
+		wall.HP = 100
+		enemy.HP = 50
+
+		s.Phase(game.PhaseBattle)
+		s.Advance(30)
+
+		s.ExpectHP(wall, 100)
+		s.ExpectHPBelow(enemy, 51)
+	})
+
+	t.Run("engineer repairs structures", func(t *testing.T) {
+		s := New(t)
+		engineer := s.Unit(s.Human, game.TypeEngineer, 5, 5)
+		wall := s.Unit(s.Human, game.TypeWall, 6, 5)
+		wall.HP = 150
+
+		s.Phase(game.PhaseBattle)
+		s.Advance(15)
+
+		s.ExpectHP(wall, 170)
+		if engineer.HealTargetID != wall.ID {
+			t.Errorf("expected engineer reporting wall %d as heal target, got %d", wall.ID, engineer.HealTargetID)
+		}
+	})
+
+	t.Run("structures repair between turns when configured", func(t *testing.T) {
+		config := game.DefaultPhaseConfig()
+		config.StructureRepairPercent = 10
+		s := New(t, WithConfig(config))
+		wall := s.Unit(s.Human, game.TypeWall, 6, 5)
+		tower := s.Unit(s.AI, game.TypeTower, 10, 5)
+		wall.HP = 195
+		tower.HP = 100
+
+		s.Phase(game.PhaseTurnEnd)
+		s.Game.State.AdvancePhase()
+
+		s.ExpectHP(wall, 200)
+		s.ExpectHP(tower, 100+tower.MaxHP/10)
+	})
+}
+
+func TestPlayerBuiltDecks(t *testing.T) {
+	rules := game.DefaultDeckRules()
+	repeat := func(card string, n int) []string {
+		cards := make([]string, n)
+		for i := range cards {
+			cards[i] = card
+		}
+		return cards
+	}
+
+	t.Run("validation lists every problem", func(t *testing.T) {
+		cards := append(repeat(game.SpellFireball, 6), game.TypeMainBase)
+		err := rules.Validate(cards)
+		var invalid *game.DeckValidationError
+		if !errors.As(err, &invalid) {
+			t.Fatalf("expected DeckValidationError, got %v", err)
+		}
+		if len(invalid.Problems) != 3 {
+			t.Errorf("expected size, pool and copy problems, got %v", invalid.Problems)
+		}
+
+		if err := rules.Validate(append(repeat(game.TypeWarrior, 30), repeat(game.TypeTower, 10)...)); err != nil {
+			t.Errorf("valid deck rejected: %v", err)
+		}
+	})
+
+	t.Run("memory store saves per owner and replaces by name", func(t *testing.T) {
+		store := game.NewMemoryDeckStore()
+		first, _ := store.SaveDeck("ana", "rush", repeat(game.TypeWarrior, 40))
+		second, _ := store.SaveDeck("ana", "rush", repeat(game.TypeTower, 40))
+		if first.ID != second.ID {
+			t.Errorf("saving with the same name should keep id %d, got %d", first.ID, second.ID)
+		}
+		if _, err := store.GetDeck("bob", first.ID); !errors.Is(err, game.ErrDeckNotFound) {
+			t.Errorf("other owners must not see the deck, got %v", err)
+		}
+		decks, _ := store.ListDecks("ana")
+		if len(decks) != 1 || decks[0].Cards[0] != game.TypeTower {
+			t.Errorf("unexpected decks %+v", decks)
+		}
+	})
+
+	t.Run("selected deck is used for draws and reshuffles", func(t *testing.T) {
+		state := game.NewGameStateWithSeed(1)
+		player := state.AddPlayerWithDeck(repeat(game.TypeWall, 40))
+		// Más robos que cartas: el mazo se rebaraja con la lista elegida, no con defaultDeck
+		for i := 0; i < 50; i++ {
+			state.DrawCard(player.ID)
+		}
+		for _, card := range player.Hand {
+			if card != game.TypeWall {
+				t.Fatalf("drew %q from a walls-only deck", card)
+			}
+		}
+		if ai := state.Players[state.AIPlayerID]; len(ai.DeckList) != 0 {
+			t.Errorf("AI should keep the default deck")
+		}
+	})
+}
+
+func cards(list ...string) map[string]any {
+	return map[string]any{"cards": list}
+}
+
+func TestMulliganAndHandLimit(t *testing.T) {
+	t.Run("mulligan only during base selection and limited", func(t *testing.T) {
+		s := New(t)
+		s.Hand(s.Human, game.TypeTower, game.TypeWall, game.TypeWarrior)
+
+		s.Command(s.Human, command.CommandMulligan, cards(game.TypeTower, game.TypeWall))
+		s.Advance(1)
+		s.ExpectNoRejected()
+		s.ExpectHandSize(s.Human, 3)
+		if hand := s.Game.State.Players[s.Human].Hand; hand[0] != game.TypeWarrior {
+			t.Errorf("kept card should stay first, hand %v", hand)
+		}
+		events := s.EventsOfType(game.EventMulligan)
+		if len(events) != 1 || events[0].OwnerID != s.Human {
+			t.Fatalf("expected one private mulligan event, got %+v", events)
+		}
+
+		s.Command(s.Human, command.CommandMulligan, cards(game.TypeWarrior))
+		s.Advance(1)
+		if reason := s.ExpectRejected(command.CommandMulligan); reason != "no mulligans left" {
+			t.Errorf("unexpected rejection reason %q", reason)
+		}
+	})
+
+	t.Run("mulligan rejects cards not in hand", func(t *testing.T) {
+		s := New(t)
+		s.Hand(s.Human, game.TypeTower)
+		s.Command(s.Human, command.CommandMulligan, cards(game.TypeTower, game.TypeTower))
+		s.Advance(1)
+		if reason := s.ExpectRejected(command.CommandMulligan); reason != "card not in hand" {
+			t.Errorf("unexpected rejection reason %q", reason)
+		}
+		s.ExpectHandSize(s.Human, 1)
+	})
+
+	t.Run("draws beyond the limit are burned", func(t *testing.T) {
+		config := game.DefaultPhaseConfig()
+		config.MaxHandSize = 2
+		s := New(t, WithConfig(config))
+		s.Hand(s.Human, game.TypeTower, game.TypeWall)
+
+		if _, ok := s.Game.State.DrawCard(s.Human); ok {
+			t.Errorf("draw with a full hand should burn the card")
+		}
+		s.Advance(1)
+		s.ExpectHandSize(s.Human, 2)
+		burned := s.EventsOfType(game.EventCardsDiscarded)
+		if len(burned) != 1 || burned[0].Data.(game.CardsDiscardedEventData).Reason != game.DiscardReasonBurn {
+			t.Errorf("expected a burn event, got %+v", burned)
+		}
+	})
+
+	t.Run("discard mode lets the player choose before battle", func(t *testing.T) {
+		config := game.DefaultPhaseConfig()
+		config.MaxHandSize = 2
+		config.HandOverflow = game.HandOverflowDiscard
+		s := New(t, WithConfig(config))
+		s.Hand(s.Human, game.TypeTower, game.TypeWall)
+		s.Game.State.DrawCard(s.Human)
+		s.Phase(game.PhasePreparation)
+		s.ExpectHandSize(s.Human, 3)
+
+		s.Command(s.Human, command.CommandDiscard, cards(game.TypeWall))
+		s.Advance(1)
+		s.ExpectNoRejected()
+		s.ExpectHandSize(s.Human, 2)
+
+		// Lo que no se descarta se quema al empezar la batalla
+		s.Game.State.DrawCard(s.Human)
+		s.Command(s.Human, command.CommandReady, nil)
+		s.Command(s.AI, command.CommandReady, nil)
+		s.Advance(2)
+		s.ExpectHandSize(s.Human, 2)
+		if got := len(s.EventsOfType(game.EventCardsDiscarded)); got != 2 {
+			t.Errorf("expected discard and burn events, got %d", got)
+		}
+	})
+}
+
+func TestFiniteDeckFatigue(t *testing.T) {
+	config := game.DefaultPhaseConfig()
+	config.FiniteDeck = true
+	config.FatigueDamage = 10
+	s := New(t, WithConfig(config))
+	base := s.Base(s.Human, 2, 5)
+	s.Base(s.AI, 17, 5)
+	s.Phase(game.PhasePreparation)
+
+	player := s.Game.State.Players[s.Human]
+	player.Deck = []string{game.TypeTower}
+	if card, ok := s.Game.State.DrawCard(s.Human); !ok || card != game.TypeTower {
+		t.Fatalf("expected to draw the last card, got %q", card)
+	}
+	if player.DeckCount != 0 {
+		t.Errorf("expected empty deck, deckCount %d", player.DeckCount)
+	}
+
+	// Sin mazo: cada robo daña la base con fatiga creciente (10, 20, 30)
+	hp := base.HP
+	for i := 0; i < 3; i++ {
+		if _, ok := s.Game.State.DrawCard(s.Human); ok {
+			t.Fatalf("drew a card from an empty finite deck")
+		}
+	}
+	s.ExpectHP(base, hp-60)
+	if player.Fatigue != 3 {
+		t.Errorf("expected fatigue 3, got %d", player.Fatigue)
+	}
+	s.Advance(1)
+	if got := len(s.EventsOfType(game.EventFatigue)); got != 3 {
+		t.Errorf("expected 3 fatigue events, got %d", got)
+	}
+
+	// La fatiga puede destruir la base y terminar la partida
+	base.HP = 30
+	s.Game.State.DrawCard(s.Human)
+	s.Advance(1)
+	s.ExpectWinner(s.AI)
+}
+
+func TestTimeBankAutoReadies(t *testing.T) {
+	config := game.DefaultPhaseConfig()
+	config.TimeBankMs = 1000 // 5 ticks de 200 ms
+	config.TimeBankIncrementMs = 400
+	config.TimeBankMaxMs = 1200
+	s := New(t, WithConfig(config))
+	s.Base(s.Human, 2, 5)
+	s.Base(s.AI, 17, 5)
+	human := s.Game.State.Players[s.Human]
+	ai := s.Game.State.Players[s.AI]
+
+	// Al empezar la preparación se recarga hasta el tope
+	s.Phase(game.PhaseTurnStart)
+	s.AdvanceUntil(config.TurnStartDuration+2, func() bool {
+		return s.Game.State.CurrentPhase == game.PhasePreparation
+	})
+	if human.TimeBankMs != 1200 {
+		t.Fatalf("expected bank topped up to 1200ms, got %d", human.TimeBankMs)
+	}
+
+	// Se descuenta por tick (200 ms cada uno) mientras no está listo; al agotarse pasa a listo y
+	// empieza la batalla. La IA no usa banco.
+	s.Advance(5)
+	if s.Game.State.CurrentPhase != game.PhasePreparation || human.TimeBankMs != 200 {
+		t.Fatalf("expected 200ms left in preparation, got %dms in %s", human.TimeBankMs, s.Game.State.CurrentPhase)
+	}
+	if ai.TimeBankMs != config.TimeBankMs {
+		t.Errorf("expected the ai bank untouched at %dms, got %d", config.TimeBankMs, ai.TimeBankMs)
+	}
+	s.Advance(2)
+	if s.Game.State.CurrentPhase != game.PhaseBattle {
+		t.Errorf("expected battle after the bank ran out, got %s", s.Game.State.CurrentPhase)
+	}
+	exhausted := false
+	for _, e := range s.EventsOfType(game.EventTimeBank) {
+		data := e.Data.(game.TimeBankEventData)
+		exhausted = exhausted || (data.PlayerID == s.Human && data.Exhausted)
+	}
+	if !exhausted {
+		t.Errorf("expected a time_bank exhausted event for the human")
+	}
+}
+
+func TestPauseResumeAndSurrender(t *testing.T) {
+	t.Run("single player pause freezes the simulation", func(t *testing.T) {
+		config := game.DefaultPhaseConfig()
+		config.MaxPauses = 1
+		config.MaxPauseSeconds = 2 // 10 ticks de 200 ms
+		s := New(t, WithConfig(config))
+		s.Base(s.Human, 2, 5)
+		s.Base(s.AI, 17, 5)
+		s.Hand(s.Human, game.TypeTower)
+		s.Phase(game.PhaseBattle)
+
+		s.Command(s.Human, command.CommandPause, nil)
+		s.Advance(1)
+		tick, phaseStart := s.Game.State.Tick, s.Game.State.PhaseStartTick
+		if !s.Game.State.IsPaused() {
+			t.Fatalf("expected the game to be paused")
+		}
+
+		s.Command(s.Human, command.CommandSpawnUnit, spawn(game.TypeTower, 4, 5))
+		s.Advance(5)
+		if reason := s.ExpectRejected(command.CommandSpawnUnit); reason != "game is paused" {
+			t.Errorf("unexpected rejection reason %q", reason)
+		}
+		if s.Game.State.Tick != tick || s.Game.State.PhaseStartTick != phaseStart {
+			t.Errorf("tick advanced while paused: %d -> %d", tick, s.Game.State.Tick)
+		}
+
+		s.Command(s.Human, command.CommandResume, nil)
+		s.Advance(1)
+		if s.Game.State.IsPaused() {
+			t.Fatalf("expected the game to resume")
+		}
+
+		// Sin pausas restantes
+		s.Command(s.Human, command.CommandPause, nil)
+		s.Advance(1)
+		if reason := s.ExpectRejected(command.CommandPause); reason != "no pauses left" {
+			t.Errorf("unexpected rejection reason %q", reason)
+		}
+	})
+
+	t.Run("pause times out", func(t *testing.T) {
+		config := game.DefaultPhaseConfig()
+		config.MaxPauseSeconds = 2
+		s := New(t, WithConfig(config))
+		s.Phase(game.PhaseBattle)
+		s.Command(s.Human, command.CommandPause, nil)
+		s.Advance(10)
+		if !s.Game.State.IsPaused() {
+			t.Fatalf("pause ended early")
+		}
+		s.Advance(1)
+		resumed := s.EventsOfType(game.EventGameResumed)
+		if s.Game.State.IsPaused() || len(resumed) != 1 || resumed[0].Data.(game.PauseEventData).Reason != game.ResumeReasonTimeout {
+			t.Errorf("expected a timeout resume, got %+v", resumed)
+		}
+	})
+
+	t.Run("pvp pause needs both players", func(t *testing.T) {
+		s := New(t)
+		rival := s.Game.State.AddPlayer().ID
+		s.Phase(game.PhaseBattle)
+
+		s.Command(s.Human, command.CommandPause, nil)
+		s.Advance(1)
+		if s.Game.State.IsPaused() || len(s.EventsOfType(game.EventPauseRequested)) != 1 {
+			t.Fatalf("expected a pending pause request")
+		}
+		s.Command(rival, command.CommandPause, nil)
+		s.Advance(1)
+		if !s.Game.State.IsPaused() || s.Game.State.Pause.PausedBy != s.Human {
+			t.Errorf("expected the pause requested by the human to be accepted, got %+v", s.Game.State.Pause)
+		}
+	})
+
+	t.Run("surrender goes through confirm_end", func(t *testing.T) {
+		s := New(t)
+		s.Base(s.Human, 2, 5)
+		s.Base(s.AI, 17, 5)
+		s.Phase(game.PhaseBattle)
+
+		s.Command(s.Human, command.CommandSurrender, nil)
+		s.Advance(1)
+		s.ExpectWinner(s.AI)
+		if reason := s.Game.State.GameEnd.Reason; reason != "surrender" {
+			t.Errorf("unexpected end reason %q", reason)
+		}
+
+		s.Command(s.Human, command.CommandConfirmEnd, nil)
+		s.Advance(1)
+		s.ExpectNoRejected()
+		if !s.Game.State.GameEnd.Confirmed {
+			t.Errorf("confirm_end should be accepted outside preparation")
+		}
+	})
+}
+
+func TestTurnLimitAndSuddenDeath(t *testing.T) {
+	// finishTurn avanza desde turn_end hasta que empieza el turno siguiente
+	finishTurn := func(s *Scenario) {
+		s.Phase(game.PhaseTurnEnd)
+		turn := s.Game.State.TurnNumber
+		s.AdvanceUntil(50, func() bool { return s.Game.State.TurnNumber > turn || s.Game.State.IsGameEndPending() })
+		s.Advance(1)
+	}
+
+	t.Run("max turns decided by base hp", func(t *testing.T) {
+		config := game.DefaultPhaseConfig()
+		config.MaxTurns = 1
+		s := New(t, WithConfig(config))
+		human := s.Base(s.Human, 2, 5)
+		s.Base(s.AI, 17, 5)
+		human.HP -= 100
+
+		finishTurn(s)
+		s.ExpectWinner(s.AI)
+		end := s.Game.State.GameEnd
+		if end.Reason != game.EndReasonMaxTurns || end.Tiebreak != game.TiebreakBaseHP || end.Scores[s.AI] != 100 {
+			t.Errorf("unexpected tiebreak result %+v", end)
+		}
+	})
+
+	t.Run("max turns tie is a draw", func(t *testing.T) {
+		config := game.DefaultPhaseConfig()
+		config.MaxTurns = 1
+		config.Tiebreak = game.TiebreakStructureValue
+		s := New(t, WithConfig(config))
+		s.Base(s.Human, 2, 5)
+		s.Base(s.AI, 17, 5)
+
+		finishTurn(s)
+		s.ExpectDraw()
+	})
+
+	t.Run("sudden death damages both bases until a draw", func(t *testing.T) {
+		config := game.DefaultPhaseConfig()
+		config.SuddenDeathTurn = 2
+		config.SuddenDeathMode = game.SuddenDeathBaseDamage
+		config.SuddenDeathBaseDamage = 200
+		s := New(t, WithConfig(config))
+		human := s.Base(s.Human, 2, 5)
+		ai := s.Base(s.AI, 17, 5)
+		hp := human.HP
+
+		finishTurn(s)
+		s.ExpectHP(human, hp-200)
+		s.ExpectHP(ai, hp-200)
+		if !game.BuildSnapshot(s.Game.State).SuddenDeath {
+			t.Errorf("snapshot should report sudden death")
+		}
+
+		// El daño crece por turno: 400 en el turno siguiente
+		for !s.Game.State.IsGameEndPending() && s.Game.State.TurnNumber < 10 {
+			finishTurn(s)
+		}
+		s.ExpectDraw()
+		if reason := s.Game.State.GameEnd.Reason; reason != game.EndReasonBothBasesDestroyed {
+			t.Errorf("unexpected end reason %q", reason)
+		}
+	})
+
+	t.Run("sudden death buffs attackers", func(t *testing.T) {
+		hit := func(opts ...Option) int {
+			s := New(t, opts...)
+			ours := s.Unit(s.Human, game.TypeLandSoldier, 5, 5)
+			s.Unit(s.AI, game.TypeLandSoldier, 6, 5)
+			s.Phase(game.PhaseBattle)
+			return firstAttack(t, s, ours.ID).Hits[0].Damage
+		}
+		config := game.DefaultPhaseConfig()
+		config.SuddenDeathTurn = 1
+		config.SuddenDeathDamageMultiplier = 2
+		if normal, buffed := hit(), hit(WithConfig(config)); buffed != 2*normal {
+			t.Errorf("expected double damage in sudden death, got %d vs %d", buffed, normal)
+		}
+	})
+}
+
+func TestMatchStatsSummary(t *testing.T) {
+	s := New(t)
+	humanBase := s.Base(s.Human, 2, 5)
+	s.Unit(s.Human, game.TypeLandGenerator, 2, 1)
+	s.Unit(s.AI, game.TypeLandSoldier, 3, 5)
+	victim := s.Unit(s.AI, game.TypeLandSoldier, 8, 5)
+	humanBase.HP = 15
+	victim.HP = 30
+	s.Hand(s.Human, game.SpellFireball)
+
+	s.Phase(game.PhasePreparation)
+	s.Command(s.Human, command.CommandPlayCard, playCard(game.SpellFireball, 8, 5))
+	s.Advance(30) // Vence el intervalo del generador: produce en el primer tick de batalla
+	s.Phase(game.PhaseBattle)
+	s.AdvanceUntil(40, s.Game.State.IsGameEndPending)
+	s.ExpectWinner(s.AI)
+
+	stats := s.Game.State.GameEnd.Stats
+	if stats == nil {
+		t.Fatalf("game end should carry the match stats")
+	}
+	human, ai := stats.Players[s.Human], stats.Players[s.AI]
+	if human.CardsPlayed[game.SpellFireball] != 1 || human.Units[game.SpellFireball].DamageDealt != 60 {
+		t.Errorf("fireball not recorded: %+v", human)
+	}
+	// La base principal también produce: el total suma ambos generadores
+	generated := human.Units[game.TypeLandGenerator].Generated + human.Units[game.TypeMainBase].Generated
+	if human.Units[game.TypeLandGenerator].Generated == 0 || human.GeneratorOutput != generated {
+		t.Errorf("generator output not recorded: %d vs %d", human.GeneratorOutput, generated)
+	}
+	if soldier := ai.Units[game.TypeLandSoldier]; soldier.Lost != 1 || soldier.DamageTaken != 60 {
+		t.Errorf("unexpected ai soldier stats %+v", soldier)
+	}
+	if ai.StructuresDestroyed != 1 || human.Units[game.TypeMainBase].Lost != 1 || human.DamageTaken < 15 {
+		t.Errorf("base kill not recorded: ai %+v, human %+v", ai, human)
+	}
+	if ai.DamageDealt != human.Units[game.TypeMainBase].DamageTaken {
+		t.Errorf("ai damage dealt %d should match base damage taken", ai.DamageDealt)
+	}
+}
+
+func TestLifecycleEvents(t *testing.T) {
+	s := New(t)
+	base := s.Base(s.Human, 2, 5)
+	generator := s.Unit(s.Human, game.TypeLandGenerator, 5, 5)
+	ours := s.Unit(s.Human, game.TypeLandSoldier, 10, 5)
+	victim := s.Unit(s.AI, game.TypeLandSoldier, 11, 5)
+	victim.HP = 1
+	s.Hand(s.Human, game.TypeLandSoldier)
+
+	s.Phase(game.PhasePreparation)
+	s.Command(s.Human, command.CommandSpawnUnit, spawn(game.TypeLandSoldier, 3, 5))
+	s.Advance(30)
+	placed := s.EventsOfType(game.EventBasePlaced)
+	if len(placed) != 1 || placed[0].Data.(game.BasePlacedEventData).BaseID != base.ID {
+		t.Errorf("expected a base_placed event, got %+v", placed)
+	}
+	played := s.EventsOfType(game.EventCardPlayed)
+	if len(played) != 1 || played[0].Data.(game.CardPlayedEventData).Card != game.TypeLandSoldier {
+		t.Errorf("expected a card_played event, got %+v", played)
+	}
+
+	s.Phase(game.PhaseBattle)
+	s.AdvanceUntil(20, func() bool { return len(s.EventsOfType(game.EventUnitDied)) > 0 })
+	died := s.EventsOfType(game.EventUnitDied)
+	if len(died) != 1 {
+		t.Fatalf("expected one unit_died event, got %+v", died)
+	}
+	if data := died[0].Data.(game.UnitDiedEventData); data.UnitID != victim.ID || data.KillerID != ours.ID || data.KillerPlayerID != s.Human {
+		t.Errorf("unexpected unit_died data %+v", data)
+	}
+
+	fromGenerator := false
+	for _, e := range s.EventsOfType(game.EventUnitSpawned) {
+		if e.Data.(game.UnitSpawnedEventData).SourceID == generator.ID {
+			fromGenerator = true
+		}
+	}
+	if !fromGenerator {
+		t.Errorf("expected a unit_spawned event from the generator")
+	}
+}
+
+func TestVeterancyFromKills(t *testing.T) {
+	config := game.DefaultPhaseConfig()
+	config.Veterancy = []game.VeterancyRank{{Kills: 2, HPBonus: 0.5, DamageBonus: 1}}
+	s := New(t, WithConfig(config))
+	veteran := s.Unit(s.Human, game.TypeLandSoldier, 5, 5)
+	first := s.Unit(s.AI, game.TypeLandSoldier, 6, 5)
+	second := s.Unit(s.AI, game.TypeLandSoldier, 5, 6)
+	third := s.Unit(s.AI, game.TypeLandSoldier, 8, 5)
+	first.HP, second.HP = 1, 1
+	maxHP := veteran.MaxHP
+	// Enemigos aturdidos: no contraatacan, el resultado no depende del orden de ataque
+	for _, enemy := range []*game.UnitState{first, second, third} {
+		s.Game.State.ApplyStatusEffect(enemy.ID, game.StatusEffect{Kind: game.EffectStun, RemainingTicks: 200})
+	}
+
+	s.Phase(game.PhaseBattle)
+	s.AdvanceUntil(40, func() bool { return veteran.Rank == 1 })
+	s.ExpectDead(first)
+	s.ExpectDead(second)
+	if veteran.Kills != 2 || veteran.MaxHP != maxHP*3/2 {
+		t.Errorf("expected rank 1 with 2 kills and +50%% max hp, got kills %d, max hp %d", veteran.Kills, veteran.MaxHP)
+	}
+	if promoted := s.EventsOfType(game.EventUnitPromoted); len(promoted) != 1 || promoted[0].Data.(game.UnitPromotedEventData).Rank != 1 {
+		t.Errorf("expected one unit_promoted event, got %+v", promoted)
+	}
+
+	// Con rango 1 el daño se duplica contra el tercer enemigo
+	normal := attacksBy(s, veteran.ID)[0].Hits[0].Damage
+	s.AdvanceUntil(40, func() bool {
+		attacks := attacksBy(s, veteran.ID)
+		return attacks[len(attacks)-1].TargetID == third.ID
+	})
+	attacks := attacksBy(s, veteran.ID)
+	if damage := attacks[len(attacks)-1].Hits[0].Damage; damage != 2*normal {
+		t.Errorf("expected veteran damage %d, got %d", 2*normal, damage)
+	}
+
+	stats := s.Game.State.GetMatchStats().Players[s.Human]
+	if stats.Kills != 2 || stats.Promotions != 1 {
+		t.Errorf("veterancy not counted in match stats: %+v", stats)
+	}
+}
+
+func TestWaveModeVictoryAfterLastWave(t *testing.T) {
+	config := game.DefaultPhaseConfig()
+	config.Mode = game.GameModeWaves
+	config.Waves = []game.WaveDef{
+		{Groups: []game.WaveGroup{{UnitType: game.TypeLandSoldier, Count: 2}}},
+		{SpawnInterval: 2, Groups: []game.WaveGroup{{UnitType: game.TypeLandSoldier, Count: 2}, {UnitType: game.TypeSiegeRam, Count: 1}}},
+	}
+	config.WaveSpawnPoints = []game.WaveSpawnPoint{{X: 18, Y: 2}, {X: 18, Y: 7}}
+	s := New(t, WithConfig(config))
+	s.Base(s.Human, 2, 5)
+
+	// Solo hace falta la base del jugador: el atacante neutral no coloca base
+	s.AdvanceUntil(5, func() bool { return s.Game.State.GetCurrentPhase() == game.PhaseTurnStart })
+	if s.Game.State.AIBaseID != 0 {
+		t.Errorf("the wave attacker should not place a base")
+	}
+
+	// fightWave llega a la próxima batalla, deja salir la oleada y la elimina
+	fightWave := func(units int) {
+		s.AdvanceUntil(400, func() bool { return s.Game.State.GetCurrentPhase() == game.PhasePreparation })
+		s.Command(s.Human, command.CommandReady, nil)
+		s.AdvanceUntil(20, func() bool { return s.Game.State.GetCurrentPhase() == game.PhaseBattle })
+		s.Advance(10)
+		attackers := s.Units(s.AI, game.TypeLandSoldier)
+		attackers = append(attackers, s.Units(s.AI, game.TypeSiegeRam)...)
+		if len(attackers) != units {
+			t.Fatalf("expected %d wave units, got %d", units, len(attackers))
+		}
+		for _, u := range attackers {
+			u.HP = 0
+		}
+		s.Advance(1)
+	}
+
+	fightWave(2)
+	waves := game.BuildSnapshot(s.Game.State).Waves
+	if waves == nil || waves.Current != 1 || waves.Total != 2 || waves.Alive != 0 {
+		t.Fatalf("unexpected wave counter %+v", waves)
+	}
+	s.ExpectNoWinner()
+
+	fightWave(3)
+	s.ExpectWinner(s.Human)
+	if reason := s.Game.State.GameEnd.Reason; reason != game.EndReasonWavesSurvived {
+		t.Errorf("unexpected end reason %q", reason)
+	}
+	if started := s.EventsOfType(game.EventWaveStarted); len(started) != 2 {
+		t.Errorf("expected two wave_started events, got %d", len(started))
+	}
+}
+
+func TestControlPointCaptureAndRewards(t *testing.T) {
+	s := New(t, WithMap(
+		"....................",
+		"....................",
+		"....................",
+		"....................",
+		"....................",
+		"..........C.........",
+		"....................",
+		"....................",
+		"....................",
+		"....................",
+	))
+	s.Base(s.Human, 1, 5)
+	s.Base(s.AI, 19, 5)
+	ours := []*game.UnitState{s.Unit(s.Human, game.TypeLandSoldier, 10, 4), s.Unit(s.Human, game.TypeLandSoldier, 10, 6)}
+	theirs := s.Unit(s.AI, game.TypeLandSoldier, 11, 5)
+	// Todos aturdidos: solo cuenta quién está dentro del radio
+	for _, unit := range append(ours, theirs) {
+		s.Game.State.ApplyStatusEffect(unit.ID, game.StatusEffect{Kind: game.EffectStun, RemainingTicks: 500})
+	}
+	capture := game.DefaultPhaseConfig().ControlPointCaptureTicks
+
+	s.Phase(game.PhaseBattle)
+	s.Advance(capture - 1)
+	point := game.BuildSnapshot(s.Game.State).ControlPoints[0]
+	if point.OwnerID != 0 || point.CapturingID != s.Human || point.Progress != capture-1 {
+		t.Fatalf("unexpected capture progress %+v", point)
+	}
+	s.Advance(1)
+	if point := game.BuildSnapshot(s.Game.State).ControlPoints[0]; point.OwnerID != s.Human {
+		t.Fatalf("expected the point to be captured, got %+v", point)
+	}
+	if captured := s.EventsOfType(game.EventControlPointCaptured); len(captured) != 1 {
+		t.Errorf("expected one control_point_captured event, got %d", len(captured))
+	}
+
+	// Empate dentro del radio: la captura del rival queda congelada
+	ours[0].HP = 0
+	s.Advance(10)
+	if point := game.BuildSnapshot(s.Game.State).ControlPoints[0]; point.OwnerID != s.Human || point.Progress != 0 {
+		t.Errorf("contested point should not change hands, got %+v", point)
+	}
+
+	// El punto extiende el área de construcción y da una carta extra por turno
+	s.Hand(s.Human, game.TypeTower)
+	s.Phase(game.PhasePreparation)
+	s.Command(s.Human, command.CommandSpawnUnit, spawn(game.TypeTower, 12, 5))
+	s.Advance(1)
+	s.ExpectNoRejected()
+	s.ExpectHandSize(s.Human, 0)
+
+	s.Phase(game.PhaseTurnEnd)
+	s.AdvanceUntil(50, func() bool { return s.Game.State.GetCurrentPhase() == game.PhaseTurnStart })
+	s.ExpectHandSize(s.Human, 2)
+}
+
+func TestTeamMatches(t *testing.T) {
+	teams := func(humans int) game.PhaseConfig {
+		config := game.DefaultPhaseConfig()
+		config.Seats = game.TwoVsTwoSeats(humans)
+		return config
+	}
+
+	t.Run("humans fill free seats and ai seats place bases after them", func(t *testing.T) {
+		s := New(t, WithConfig(teams(2)))
+		partner := s.Game.State.AddPlayer()
+		if partner == nil || partner.ID != 2 || partner.Team != 1 {
+			t.Fatalf("expected the second human in seat 2 of team 1, got %+v", partner)
+		}
+		if s.Game.State.AddPlayer() != nil {
+			t.Errorf("expected no free seats left")
+		}
+		if s.AI != 3 || !s.Game.State.AreAllied(s.Human, partner.ID) || s.Game.State.AreAllied(s.Human, s.AI) {
+			t.Errorf("unexpected teams: ai=%d players=%v", s.AI, s.Game.State.Players)
+		}
+
+		s.Base(s.Human, 1, 1)
+		s.Advance(1)
+		if s.Game.State.HasPlayerPlacedBase(3) {
+			t.Fatalf("ai seats must wait for every human base")
+		}
+		s.Base(partner.ID, 1, 8)
+		s.Advance(2)
+		for _, id := range []int{3, 4} {
+			if !s.Game.State.HasPlayerPlacedBase(id) {
+				t.Errorf("ai seat %d did not place its base", id)
+			}
+		}
+		if s.Game.State.GetCurrentPhase() == game.PhaseBaseSelection {
+			t.Errorf("expected the match to start once all four bases are placed")
+		}
+	})
+
+	t.Run("allies never target each other", func(t *testing.T) {
+		s := New(t, WithConfig(teams(1)))
+		tower := s.Unit(s.Human, game.TypeTower, 5, 5)
+		ally := s.Unit(2, game.TypeLandSoldier, 7, 5)
+		s.Game.State.ApplyStatusEffect(ally.ID, game.StatusEffect{Kind: game.EffectStun, RemainingTicks: 500})
+
+		s.Phase(game.PhaseBattle)
+		s.Advance(20)
+		if attacks := attacksBy(s, tower.ID); len(attacks) != 0 {
+			t.Fatalf("tower attacked its ally: %+v", attacks)
+		}
+
+		enemy := s.Unit(4, game.TypeLandSoldier, 9, 5)
+		s.Game.State.ApplyStatusEffect(enemy.ID, game.StatusEffect{Kind: game.EffectStun, RemainingTicks: 500})
+		if attack := firstAttack(t, s, tower.ID); attack.TargetID != enemy.ID {
+			t.Errorf("expected the tower to attack the enemy seat, got target %d", attack.TargetID)
+		}
+	})
+
+	t.Run("support units heal and repair teammates", func(t *testing.T) {
+		s := New(t, WithConfig(teams(1)))
+		medic := s.Unit(s.Human, game.TypeMedic, 0, 5)
+		soldier := s.Unit(2, game.TypeLandSoldier, 6, 5)
+		soldier.HP = 40
+		s.Unit(s.Human, game.TypeEngineer, 10, 2)
+		wall := s.Unit(2, game.TypeWall, 11, 2)
+		wall.HP = 150
+
+		s.Phase(game.PhaseBattle)
+		s.Advance(100)
+
+		s.ExpectHP(soldier, 100)
+		if medic.HealedTotal != 60 {
+			t.Errorf("expected the medic to heal its teammate's soldier by 60, got %d", medic.HealedTotal)
+		}
+		s.ExpectHP(wall, 200)
+	})
+
+	t.Run("shared build area is optional", func(t *testing.T) {
+		for _, shared := range []bool{false, true} {
+			config := teams(1)
+			config.SharedBuildArea = shared
+			s := New(t, WithConfig(config))
+			s.Base(s.Human, 0, 0)
+			s.Base(2, 19, 9)
+			s.Hand(s.Human, game.TypeTower)
+			s.Phase(game.PhasePreparation)
+
+			s.Command(s.Human, command.CommandSpawnUnit, spawn(game.TypeTower, 17, 9))
+			s.Advance(1)
+			if built := len(s.Units(s.Human, game.TypeTower)) == 1; built != shared {
+				t.Errorf("sharedBuildArea=%v: tower next to the ally base built=%v", shared, built)
+			}
+		}
+	})
+
+	t.Run("a team loses when all its bases fall", func(t *testing.T) {
+		s := New(t, WithConfig(teams(1)))
+		s.Base(s.Human, 1, 1)
+		s.Base(2, 1, 8)
+		enemyBases := []*game.UnitState{s.Base(3, 18, 1), s.Base(4, 18, 8)}
+		s.Phase(game.PhasePreparation)
+
+		enemyBases[0].HP = 0
+		s.Advance(1)
+		s.ExpectNoWinner()
+
+		enemyBases[1].HP = 0
+		s.Advance(1)
+		s.ExpectWinner(s.Human)
+		if end := s.Game.State.GameEnd; end.Reason != game.EndReasonTeamDestroyed || end.WinnerTeam != 1 || end.LoserTeam != 2 {
+			t.Errorf("unexpected team result %+v", end)
+		}
+	})
+}
+
+func TestEventReplayOnResume(t *testing.T) {
+	t.Run("missed events are replayed in order without other players' private events", func(t *testing.T) {
+		s := New(t)
+		s.Hand(s.Human, game.TypeTower, game.TypeWall)
+		s.Command(s.Human, command.CommandMulligan, cards(game.TypeTower))
+		s.Advance(1)
+		s.ExpectNoRejected()
+		s.Base(s.Human, 2, 5)
+		s.Base(s.AI, 17, 5)
+		s.Phase(game.PhaseBattle)
+		s.Advance(5)
+
+		if len(s.Events) < 2 {
+			t.Fatalf("expected several events, got %+v", s.Events)
+		}
+		for i := 1; i < len(s.Events); i++ {
+			if s.Events[i].Seq <= s.Events[i-1].Seq {
+				t.Fatalf("event seqs should increase, got %d after %d", s.Events[i].Seq, s.Events[i-1].Seq)
+			}
+		}
+
+		mulligan := s.EventsOfType(game.EventMulligan)[0]
+		missed, complete := s.Game.State.EventsSince(s.Human, mulligan.Seq-1)
+		if !complete || len(missed) == 0 || missed[0].Seq != mulligan.Seq {
+			t.Fatalf("human should get every event from the mulligan on, got complete=%v %+v", complete, missed)
+		}
+		aiEvents, _ := s.Game.State.EventsSince(s.AI, 0)
+		for _, e := range aiEvents {
+			if e.Type == game.EventMulligan {
+				t.Errorf("AI should not receive the human's private mulligan event")
+			}
+		}
+
+		resume := game.BuildResumeMessage(s.Game.State, s.Human, s.Game.State.LastEventSeq())
+		if resume.Type != "resume" || !resume.Complete || len(resume.Events) != 0 {
+			t.Errorf("an up to date client should get no events, got %+v", resume)
+		}
+		if resume.State.Tick != s.Game.State.Tick {
+			t.Errorf("resume state should be the current tick %d, got %d", s.Game.State.Tick, resume.State.Tick)
+		}
+	})
+
+	t.Run("replay is incomplete once the buffer dropped missed events", func(t *testing.T) {
+		config := game.DefaultPhaseConfig()
+		config.MaxMulligans = 2000
+		s := New(t, WithConfig(config))
+		s.Hand(s.Human, game.TypeTower)
+		for i := 0; i < 1100; i++ {
+			if err := s.Game.State.Mulligan(s.Human, s.Game.State.Players[s.Human].Hand); err != nil {
+				t.Fatalf("mulligan %d: %v", i, err)
+			}
+		}
+
+		missed, complete := s.Game.State.EventsSince(s.Human, 0)
+		if complete {
+			t.Errorf("replay from seq 0 should be incomplete after %d events", s.Game.State.LastEventSeq())
+		}
+		if len(missed) == 0 || missed[len(missed)-1].Seq != s.Game.State.LastEventSeq() {
+			t.Errorf("replay should still end at the last event")
+		}
+		recent := s.Game.State.LastEventSeq() - 10
+		if missed, complete := s.Game.State.EventsSince(s.Human, recent); !complete || len(missed) != 10 {
+			t.Errorf("recent events should replay completely, got complete=%v len=%d", complete, len(missed))
+		}
+	})
+}
+
+func TestGeneratorProducesOnlyDuringBattle(t *testing.T) {
+	s := New(t)
+	s.Unit(s.Human, game.TypeLandGenerator, 5, 5)
+
+	s.Phase(game.PhasePreparation)
+	s.Advance(40)
+	s.ExpectUnitCount(s.Human, game.TypeLandSoldier, 0)
+
+	// El intervalo ya venció durante la preparación: produce en el primer tick de batalla
+	s.Phase(game.PhaseBattle)
+	s.Advance(1)
+	s.ExpectUnitCount(s.Human, game.TypeLandSoldier, 1)
+
+	// Luego respeta GenerationInterval (25 ticks)
+	s.Advance(24)
+	s.ExpectUnitCount(s.Human, game.TypeLandSoldier, 1)
+	s.Advance(1)
+	s.ExpectUnitCount(s.Human, game.TypeLandSoldier, 2)
+
+	for _, u := range s.Units(s.Human, game.TypeLandSoldier) {
+		if dist := abs(u.X-5) + abs(u.Y-5); dist > 1 {
+			t.Errorf("generated unit spawned %d tiles away from its generator", dist)
+		}
+	}
+}
+
+func TestBuildAreaLimitsSpawnPositions(t *testing.T) {
+	s := New(t)
+	s.Base(s.Human, 2, 5)
+	s.Hand(s.Human, game.TypeTower, game.TypeTower)
+	s.Phase(game.PhasePreparation)
+
+	s.Command(s.Human, command.CommandSpawnUnit, spawn(game.TypeTower, 6, 5))
+	s.Advance(1)
+	s.ExpectNoRejected()
+	s.ExpectUnitCount(s.Human, game.TypeTower, 1)
+	s.ExpectHandSize(s.Human, 1)
+
+	// Distancia Manhattan 17 desde la base y 13 desde la torre: fuera de ambos BuildRange (10)
+	s.Command(s.Human, command.CommandSpawnUnit, spawn(game.TypeTower, 19, 0))
+	s.Advance(1)
+	s.ExpectRejected(command.CommandSpawnUnit)
+	s.ExpectUnitCount(s.Human, game.TypeTower, 1)
+	s.ExpectHandSize(s.Human, 1)
+}
+
+func TestSpawnRequiresCardInHand(t *testing.T) {
+	s := New(t)
+	s.Base(s.Human, 2, 5)
+	s.Phase(game.PhasePreparation)
+
+	s.Command(s.Human, command.CommandSpawnUnit, spawn(game.TypeTower, 4, 5))
+	s.Advance(1)
+
+	if reason := s.ExpectRejected(command.CommandSpawnUnit); reason != "card not in hand" {
+		t.Errorf("unexpected rejection reason %q", reason)
+	}
+	s.ExpectUnitCount(s.Human, game.TypeTower, 0)
+}
+
+func TestNavalPlacementNeedsWater(t *testing.T) {
+	s := New(t, WithMap(
+		"..........~~",
+		"..........~~",
+		"..........~~",
+		"..........~~",
+		"..........~~",
+	))
+	s.Base(s.Human, 2, 2)
+	s.Hand(s.Human, game.TypeNavalGenerator, game.TypeNavalGenerator, game.TypeNavalShip, game.TypeNavalShip)
+	s.Phase(game.PhasePreparation)
+
+	// Generador naval tierra adentro: sin agua adyacente
+	s.Command(s.Human, command.CommandSpawnUnit, spawn(game.TypeNavalGenerator, 5, 2))
+	// Barco sobre tierra
+	s.Command(s.Human, command.CommandSpawnUnit, spawn(game.TypeNavalShip, 6, 2))
+	s.Advance(1)
+	if len(s.Rejected) != 2 {
+		t.Fatalf("expected 2 rejected commands, got %v", s.Rejected)
+	}
+	s.Rejected = nil
+
+	// Generador naval en la costa y barco sobre el agua
+	s.Command(s.Human, command.CommandSpawnUnit, spawn(game.TypeNavalGenerator, 9, 2))
+	s.Command(s.Human, command.CommandSpawnUnit, spawn(game.TypeNavalShip, 10, 1))
+	s.Advance(1)
+	s.ExpectNoRejected()
+	s.ExpectUnitCount(s.Human, game.TypeNavalGenerator, 1)
+	s.ExpectUnitCount(s.Human, game.TypeNavalShip, 1)
+	s.ExpectHandSize(s.Human, 2)
+}
+
+func TestVictoryWhenMainBaseFalls(t *testing.T) {
+	s := New(t)
+	humanBase := s.Base(s.Human, 2, 5)
+	s.Unit(s.AI, game.TypeLandSoldier, 3, 5)
+	humanBase.HP = 15
+
+	s.Phase(game.PhaseBattle)
+	s.AdvanceUntil(40, s.Game.State.IsGameEndPending)
+
+	s.ExpectDead(humanBase)
+	s.ExpectWinner(s.AI)
+	if reason := s.Game.State.GameEnd.Reason; reason != "human_base_destroyed" {
+		t.Errorf("unexpected end reason %q", reason)
+	}
+}
+
+func TestNoWinnerWhileBothBasesStand(t *testing.T) {
+	s := New(t)
+	s.Base(s.Human, 2, 5)
+	s.Base(s.AI, 17, 5)
+
+	s.Phase(game.PhasePreparation)
+	s.Advance(20)
+
+	s.ExpectNoWinner()
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,45 @@
+package game
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// La simulación registra cada tick con slog; en las pruebas solo interesa el resultado
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// playAIGame juega una partida IA vs IA con la seed dada hasta el turno indicado
+func playAIGame(t *testing.T, seed int64, turns int) []byte {
+	t.Helper()
+
+	g := NewGameWithSeed(1, seed, DefaultPhaseConfig())
+	player1 := g.State.AddPlayer()
+	g.State.SetPlayerAI(player1.ID, true)
+
+	for g.State.TurnNumber <= turns && g.State.Tick < 5000 {
+		g.Simulation.ProcessTick()
+		if gameOver, _, _ := g.Simulation.CheckVictoryConditions(); gameOver {
+			break
+		}
+	}
+
+	data, err := json.Marshal(BuildSnapshot(g.State))
+	if err != nil {
+		t.Fatalf("marshal snapshot: %v", err)
+	}
+	return data
+}
+
+func TestSameSeedReplaysIdentically(t *testing.T) {
+	first := playAIGame(t, 7, 2)
+	second := playAIGame(t, 7, 2)
+	if string(first) != string(second) {
+		t.Fatal("two games with the same seed ended in different snapshots")
+	}
+}
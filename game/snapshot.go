@@ -17,12 +17,21 @@ type Snapshot struct {
 	Config            PhaseConfig        `json:"config"`            // Configuración de fases
 	CurrentPlayerTurn int                `json:"currentPlayerTurn"` // ID del jugador cuyo turno es
 	GameEnd           *GameEndInfo       `json:"gameEnd,omitempty"`
+	Pause             *PauseState        `json:"pause,omitempty"`
+	SuddenDeath       bool               `json:"suddenDeath"`     // Muerte súbita activa este turno
+	Waves             *WaveState         `json:"waves,omitempty"` // Solo en modo waves
+	ControlPoints     []ControlPoint     `json:"controlPoints,omitempty"`
 }
 
 func BuildSnapshot(state *GameState) Snapshot {
 	state.mu.Lock()
 	defer state.mu.Unlock()
 
+	return buildSnapshotLocked(state)
+}
+
+// buildSnapshotLocked es BuildSnapshot con el lock del estado ya tomado
+func buildSnapshotLocked(state *GameState) Snapshot {
 	// Copiar unidades para evitar race conditions
 	unitsCopy := make(map[int]*UnitState, len(state.Units))
 	for id, unit := range state.Units {
@@ -45,8 +54,31 @@ func BuildSnapshot(state *GameState) Snapshot {
 			SpawnedByID:       unit.SpawnedByID,
 			TargetID:          unit.TargetID,
 			IsTargetable:      unit.IsTargetable,
+			TargetPriority:    unit.TargetPriority,
+			FocusTargetID:     unit.FocusTargetID,
+			HasOrder:          unit.HasOrder,
+			Stance:            unit.Stance,
+			GuardX:            unit.GuardX,
+			GuardY:            unit.GuardY,
+			LeashRadius:       unit.LeashRadius,
+			BreachTargetID:    unit.BreachTargetID,
+			WallMask:          unit.WallMask,
+			WallLineID:        unit.WallLineID,
 			IsBlocker:         unit.IsBlocker,
 			AttackDPS:         unit.AttackDPS,
+			DamageType:        unit.DamageType,
+			ArmorClass:        unit.ArmorClass,
+			SplashRadius:      unit.SplashRadius,
+			SplashFalloff:     unit.SplashFalloff,
+			Effects:           copyEffects(unit.Effects),
+			HealAmount:        unit.HealAmount,
+			HealRange:         unit.HealRange,
+			HealTargets:       unit.HealTargets,
+			HealTargetID:      unit.HealTargetID,
+			HealedTotal:       unit.HealedTotal,
+			Kills:             unit.Kills,
+			DamageDealt:       unit.DamageDealt,
+			Rank:              unit.Rank,
 		}
 	}
 
@@ -55,23 +87,29 @@ func BuildSnapshot(state *GameState) Snapshot {
 	for id, player := range state.Players {
 		handCopy := make([]string, len(player.Hand))
 		copy(handCopy, player.Hand)
-		readyFlag := false
-		switch id {
-		case state.HumanPlayerID:
-			readyFlag = state.HumanPlayerReady
-		case state.AIPlayerID:
-			readyFlag = state.AIPlayerReady
-		}
 		playersCopy[id] = &Player{
 			ID:        player.ID,
 			IsAI:      player.IsAI,
+			Team:      player.Team,
+			BaseID:    player.BaseID,
 			Hand:      handCopy,
 			DeckCount: player.DeckCount,
 			Connected: player.Connected || player.IsAI, // AI siempre online
-			Ready:     readyFlag,
+			Ready:     player.Ready,
+
+			MulligansUsed: player.MulligansUsed,
+			Fatigue:       player.Fatigue,
+			TimeBankMs:    player.TimeBankMs,
+			PausesUsed:    player.PausesUsed,
 		}
 	}
 
+	var pause *PauseState
+	if state.Pause != nil {
+		pauseCopy := *state.Pause
+		pause = &pauseCopy
+	}
+
 	// Determinar quién es el jugador actual del turno
 	// Durante preparation, ambos jugadores pueden actuar (currentPlayerTurn = 0)
 	currentPlayerTurn := 0
@@ -99,5 +137,9 @@ func BuildSnapshot(state *GameState) Snapshot {
 		Config:            state.Config,
 		CurrentPlayerTurn: currentPlayerTurn,
 		GameEnd:           state.GameEnd,
+		Pause:             pause,
+		SuddenDeath:       state.suddenDeathLocked(),
+		Waves:             state.waveSnapshotLocked(),
+		ControlPoints:     state.controlPointsSnapshotLocked(),
 	}
 }
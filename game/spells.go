@@ -0,0 +1,245 @@
+package game
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// Cartas de hechizo: no generan unidades, se juegan con play_card
+const (
+	SpellFireball = "fireball"  // Daño en área sobre un tile + quemadura
+	SpellRally    = "rally"     // Buff temporal de velocidad y ataque a las unidades propias
+	SpellHealWave = "heal_wave" // Cura en área a las unidades propias
+	SpellReveal   = "reveal"    // Expone una unidad enemiga: puede ser atacada aunque no sea objetivo normal
+)
+
+// SpellTargeting define qué necesita un hechizo como objetivo
+type SpellTargeting string
+
+const (
+	SpellTargetTile SpellTargeting = "tile" // Un tile (x, y) a rango
+	SpellTargetUnit SpellTargeting = "unit" // Una unidad (unitId) a rango
+	SpellTargetSelf SpellTargeting = "self" // Sin objetivo: afecta al propio jugador
+)
+
+// SpellDef define las reglas de un hechizo
+type SpellDef struct {
+	Targeting SpellTargeting `json:"targeting"`
+	// Distancia máxima (Manhattan) desde alguna estructura propia al objetivo (tile/unit)
+	Range  int         `json:"range"`
+	Radius int         `json:"radius"` // Radio del área de efecto (0 = solo el objetivo)
+	Amount int         `json:"amount"` // Daño o curación base
+	Phases []GamePhase `json:"phases"` // Fases en las que se puede jugar
+
+	// TargetEnemy indica si el objetivo unit debe ser enemigo (true) o propio (false)
+	TargetEnemy bool `json:"targetEnemy"`
+
+	apply func(g *GameState, cast *spellCast)
+}
+
+// spellCast es una invocación validada de un hechizo
+type spellCast struct {
+	PlayerID int
+	Card     string
+	Def      SpellDef
+	X, Y     int        // Tile objetivo (o el de la unidad objetivo)
+	Unit     *UnitState // Unidad objetivo (solo SpellTargetUnit)
+	Hits     []SpellHit
+}
+
+// SpellHit describe el efecto de un hechizo sobre una unidad
+type SpellHit struct {
+	UnitID   int `json:"unitId"`
+	PlayerID int `json:"playerId"`
+	Amount   int `json:"amount"` // Daño o curación aplicada (0 para efectos sin valor)
+	HP       int `json:"hp"`
+}
+
+// SpellCastEventData es el payload de un evento "spell_cast"
+type SpellCastEventData struct {
+	PlayerID int        `json:"playerId"`
+	Card     string     `json:"card"`
+	X        int        `json:"x,omitempty"`
+	Y        int        `json:"y,omitempty"`
+	UnitID   int        `json:"unitId,omitempty"`
+	Hits     []SpellHit `json:"hits"`
+}
+
+var spellDefs = map[string]SpellDef{
+	SpellFireball: {
+		Targeting: SpellTargetTile,
+		Range:     12,
+		Radius:    2,
+		Amount:    60,
+		Phases:    []GamePhase{PhasePreparation, PhaseBattle},
+		apply: func(g *GameState, cast *spellCast) {
+			friendlyFire := g.Config.FriendlyFire
+			for _, unit := range g.unitsInAreaLocked(cast.X, cast.Y, cast.Def.Radius) {
+				if !unit.targetable() || (g.alliedLocked(unit.PlayerID, cast.PlayerID) && !friendlyFire) {
+					continue
+				}
+				dist := abs(unit.X-cast.X) + abs(unit.Y-cast.Y)
+				damage := ApplyDamageMultiplier(cast.Def.Amount, FalloffLinear.Factor(dist, cast.Def.Radius))
+				damage = g.applyDamageLocked(unit, damage)
+				g.Stats.recordDamage(cast.PlayerID, cast.Card, unit, damage)
+				g.applyStatusEffectLocked(unit, StatusEffect{Kind: EffectBurn, RemainingTicks: 15, Magnitude: 5})
+				cast.hit(unit, damage)
+			}
+		},
+	},
+	SpellRally: {
+		Targeting: SpellTargetSelf,
+		Amount:    50, // Duración en ticks de batalla
+		Phases:    []GamePhase{PhasePreparation, PhaseBattle},
+		apply: func(g *GameState, cast *spellCast) {
+			for _, unit := range g.sortedUnitsLocked() {
+				if unit.PlayerID != cast.PlayerID || !unit.CanMove || unit.HP <= 0 {
+					continue
+				}
+				g.applyStatusEffectLocked(unit, StatusEffect{Kind: EffectRally, RemainingTicks: cast.Def.Amount, Magnitude: 0.25})
+				cast.hit(unit, 0)
+			}
+		},
+	},
+	SpellHealWave: {
+		Targeting: SpellTargetTile,
+		Range:     12,
+		Radius:    3,
+		Amount:    40,
+		Phases:    []GamePhase{PhasePreparation, PhaseBattle},
+		apply: func(g *GameState, cast *spellCast) {
+			for _, unit := range g.unitsInAreaLocked(cast.X, cast.Y, cast.Def.Radius) {
+				if unit.PlayerID != cast.PlayerID || unit.Category == CategoryStructure {
+					continue
+				}
+				if healed := g.healLocked(0, unit, cast.Def.Amount); healed > 0 {
+					cast.hit(unit, healed)
+				}
+			}
+		},
+	},
+	SpellReveal: {
+		Targeting:   SpellTargetUnit,
+		Range:       20,
+		Amount:      100, // Duración en ticks de batalla
+		Phases:      []GamePhase{PhasePreparation, PhaseBattle},
+		TargetEnemy: true,
+		apply: func(g *GameState, cast *spellCast) {
+			g.applyStatusEffectLocked(cast.Unit, StatusEffect{Kind: EffectReveal, RemainingTicks: cast.Def.Amount})
+			cast.hit(cast.Unit, 0)
+		},
+	},
+}
+
+// IsSpellCard indica si una carta es un hechizo (se juega con play_card, no con spawn_unit)
+func IsSpellCard(card string) bool {
+	_, ok := spellDefs[card]
+	return ok
+}
+
+// GetSpellDef retorna la definición de un hechizo
+func GetSpellDef(card string) (SpellDef, bool) {
+	def, ok := spellDefs[card]
+	return def, ok
+}
+
+// SpellDefs retorna una copia de las definiciones de todos los hechizos
+func SpellDefs() map[string]SpellDef {
+	defs := make(map[string]SpellDef, len(spellDefs))
+	for card, def := range spellDefs {
+		defs[card] = def
+	}
+	return defs
+}
+
+func (c *spellCast) hit(unit *UnitState, amount int) {
+	c.Hits = append(c.Hits, SpellHit{UnitID: unit.ID, PlayerID: unit.PlayerID, Amount: amount, HP: unit.HP})
+}
+
+// unitsInAreaLocked retorna las unidades vivas a distancia Manhattan <= radius de (x, y)
+// (requiere lock tomado)
+func (g *GameState) unitsInAreaLocked(x, y, radius int) []*UnitState {
+	var out []*UnitState
+	for _, unit := range g.sortedUnitsLocked() {
+		if unit.HP > 0 && abs(unit.X-x)+abs(unit.Y-y) <= radius {
+			out = append(out, unit)
+		}
+	}
+	return out
+}
+
+// inSpellRangeLocked indica si (x, y) está a Range de alguna estructura viva del jugador
+// (requiere lock tomado)
+func (g *GameState) inSpellRangeLocked(playerID, x, y, spellRange int) bool {
+	for _, unit := range g.Units {
+		if unit.PlayerID != playerID || unit.Category != CategoryStructure || unit.HP <= 0 {
+			continue
+		}
+		if abs(unit.X-x)+abs(unit.Y-y) <= spellRange {
+			return true
+		}
+	}
+	return false
+}
+
+// PlayCard juega una carta de hechizo de la mano del jugador. Valida la carta, la mano, la fase,
+// el objetivo y el rango; aplica el efecto, consume la carta y emite el evento "spell_cast".
+// Para SpellTargetTile se usan (x, y); para SpellTargetUnit, unitID.
+func (g *GameState) PlayCard(playerID int, card string, x, y, unitID int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	def, ok := spellDefs[card]
+	if !ok {
+		return errors.New("not a spell card")
+	}
+	if !g.hasCardInHandLocked(playerID, card) {
+		return errors.New("card not in hand")
+	}
+	phaseOK := false
+	for _, phase := range def.Phases {
+		if phase == g.CurrentPhase {
+			phaseOK = true
+			break
+		}
+	}
+	if !phaseOK {
+		return errors.New("card cannot be played in this phase")
+	}
+
+	cast := &spellCast{PlayerID: playerID, Card: card, Def: def}
+	switch def.Targeting {
+	case SpellTargetTile:
+		if _, ok := g.Map.GetTile(x, y); !ok {
+			return errors.New("invalid target tile")
+		}
+		cast.X, cast.Y = x, y
+	case SpellTargetUnit:
+		unit, ok := g.Units[unitID]
+		if !ok || unit.HP <= 0 {
+			return errors.New("target unit not found")
+		}
+		// Hechizos ofensivos sobre rivales; el resto solo sobre unidades propias
+		if def.TargetEnemy == g.alliedLocked(unit.PlayerID, playerID) || (!def.TargetEnemy && unit.PlayerID != playerID) {
+			return errors.New("invalid target unit")
+		}
+		cast.Unit = unit
+		cast.X, cast.Y = unit.X, unit.Y
+	}
+	if def.Targeting != SpellTargetSelf && !g.inSpellRangeLocked(playerID, cast.X, cast.Y, def.Range) {
+		return errors.New("target out of range")
+	}
+
+	def.apply(g, cast)
+	g.consumeCardLocked(playerID, card)
+	g.emitEventLocked(EventSpellCast, SpellCastEventData{
+		PlayerID: playerID,
+		Card:     card,
+		X:        cast.X,
+		Y:        cast.Y,
+		UnitID:   unitID,
+		Hits:     cast.Hits,
+	})
+	slog.Info("Spell cast", "tick", g.Tick, "playerId", playerID, "card", card, "x", cast.X, "y", cast.Y, "hits", len(cast.Hits))
+	return nil
+}
@@ -0,0 +1,204 @@
+package game
+
+import (
+	"errors"
+	"math"
+)
+
+// Stance define cómo reacciona una unidad móvil a los enemigos
+type Stance string
+
+const (
+	StanceAggressive Stance = "aggressive" // Persigue enemigos en su rango de detección (por defecto)
+	StanceDefensive  Stance = "defensive"  // Solo combate dentro de LeashRadius de su punto de guardia
+	StanceHold       Stance = "hold"       // Nunca se mueve; solo ataca lo que tiene a rango
+)
+
+// DefaultLeashRadius es el radio de correa por defecto de la postura defensiva
+const DefaultLeashRadius = 5
+
+// IsValid indica si la postura es una de las soportadas
+func (s Stance) IsValid() bool {
+	switch s {
+	case StanceAggressive, StanceDefensive, StanceHold:
+		return true
+	}
+	return false
+}
+
+// withinLeash indica si un tile está dentro de la correa del punto de guardia
+func (u *UnitState) withinLeash(x, y int) bool {
+	return abs(x-u.GuardX)+abs(y-u.GuardY) <= u.LeashRadius
+}
+
+// giveOrderLocked fija un destino ordenado por el jugador. Las unidades defensivas mueven
+// además su punto de guardia al destino (requiere lock tomado).
+func (g *GameState) giveOrderLocked(unit *UnitState, x, y int) {
+	unit.TargetX = x
+	unit.TargetY = y
+	unit.OrderX = x
+	unit.OrderY = y
+	unit.HasOrder = true
+	if unit.Stance == StanceDefensive {
+		unit.GuardX = x
+		unit.GuardY = y
+	}
+}
+
+// ownedMobileUnitsLocked valida que todas las unidades existan, estén vivas, sean del jugador
+// y puedan moverse (requiere lock tomado)
+func (g *GameState) ownedMobileUnitsLocked(playerID int, unitIDs []int) ([]*UnitState, error) {
+	if len(unitIDs) == 0 {
+		return nil, errors.New("no units")
+	}
+	units := make([]*UnitState, 0, len(unitIDs))
+	seen := make(map[int]bool, len(unitIDs))
+	for _, id := range unitIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unit, ok := g.Units[id]
+		if !ok || unit.HP <= 0 {
+			return nil, errors.New("unit not found")
+		}
+		if unit.PlayerID != playerID {
+			return nil, errors.New("unit not owned by player")
+		}
+		if !unit.CanMove {
+			return nil, errors.New("unit cannot move")
+		}
+		units = append(units, unit)
+	}
+	return units, nil
+}
+
+// MoveGroup mueve un conjunto de unidades del jugador en formación: el centro del grupo va a
+// (x, y) y cada unidad conserva su desplazamiento relativo. El grupo avanza al ritmo de su
+// miembro más lento. Se rechaza completo si alguna unidad no es válida.
+func (g *GameState) MoveGroup(playerID int, unitIDs []int, x, y int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	units, err := g.ownedMobileUnitsLocked(playerID, unitIDs)
+	if err != nil {
+		return err
+	}
+
+	sumX, sumY, slowest := 0, 0, 0
+	for _, unit := range units {
+		if unit.Stance == StanceHold {
+			return errors.New("unit is holding position")
+		}
+		sumX += unit.X
+		sumY += unit.Y
+		if unit.MoveIntervalTicks > slowest {
+			slowest = unit.MoveIntervalTicks
+		}
+	}
+	centerX := int(math.Round(float64(sumX) / float64(len(units))))
+	centerY := int(math.Round(float64(sumY) / float64(len(units))))
+
+	for _, unit := range units {
+		destX := clamp(x+unit.X-centerX, 0, g.Map.Width-1)
+		destY := clamp(y+unit.Y-centerY, 0, g.Map.Height-1)
+		g.giveOrderLocked(unit, destX, destY)
+		if len(units) > 1 {
+			unit.FormationInterval = slowest
+		}
+	}
+	return nil
+}
+
+// SetStance cambia la postura de unidades del jugador. Para defensive, el punto de guardia es
+// (guardX, guardY) si hasGuard o la posición actual de cada unidad; leash <= 0 usa
+// DefaultLeashRadius. La postura se mantiene entre turnos.
+func (g *GameState) SetStance(playerID int, unitIDs []int, stance Stance, guardX, guardY int, hasGuard bool, leash int) error {
+	if !stance.IsValid() {
+		return errors.New("invalid stance")
+	}
+	if leash <= 0 {
+		leash = DefaultLeashRadius
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	units, err := g.ownedMobileUnitsLocked(playerID, unitIDs)
+	if err != nil {
+		return err
+	}
+	for _, unit := range units {
+		unit.Stance = stance
+		unit.GuardX, unit.GuardY = unit.X, unit.Y
+		if hasGuard {
+			unit.GuardX, unit.GuardY = guardX, guardY
+		}
+		unit.LeashRadius = leash
+		unit.HasOrder = false
+		unit.FormationInterval = 0
+		if stance != StanceAggressive {
+			unit.TargetX, unit.TargetY = unit.GuardX, unit.GuardY
+		}
+		if stance == StanceHold {
+			unit.TargetX, unit.TargetY = unit.X, unit.Y
+		}
+	}
+	return nil
+}
+
+// updateStanceTargetLocked actualiza el objetivo de una unidad en postura defensive o hold.
+// hold solo mira enemigos a rango de ataque y nunca cambia de tile; defensive persigue enemigos
+// que estén dentro de la correa de su punto de guardia y, si no hay, vuelve a él
+// (requiere lock tomado).
+func (s *GameSimulation) updateStanceTargetLocked(unit *UnitState) {
+	if unit.Stance == StanceHold {
+		target := s.state.focusTargetLocked(unit)
+		if target == nil || abs(unit.X-target.X)+abs(unit.Y-target.Y) > unit.AttackRange {
+			target = s.state.selectTargetLocked(unit, unit.AttackRange)
+		}
+		unit.TargetX, unit.TargetY = unit.X, unit.Y
+		unit.TargetID = 0
+		if target != nil {
+			unit.TargetID = target.ID
+		}
+		return
+	}
+
+	inLeash := func(candidate *UnitState) bool { return unit.withinLeash(candidate.X, candidate.Y) }
+	target := s.state.focusTargetLocked(unit)
+	if target != nil && !inLeash(target) {
+		target = nil
+	}
+	if target == nil {
+		target = s.state.selectTargetWhereLocked(unit, unit.DetectionRange, inLeash)
+	}
+	if target != nil {
+		unit.TargetX, unit.TargetY = target.X, target.Y
+		unit.TargetID = target.ID
+	} else {
+		unit.TargetX, unit.TargetY = unit.GuardX, unit.GuardY
+		unit.TargetID = 0
+	}
+	s.applyBreachTargetLocked(unit)
+}
+
+// moveInterval retorna el intervalo de movimiento efectivo: el de la formación si la
+// unidad marcha en grupo, escalado por los efectos activos
+func (u *UnitState) moveInterval() int {
+	interval := u.MoveIntervalTicks
+	if u.HasOrder && u.FormationInterval > interval {
+		interval = u.FormationInterval
+	}
+	return u.scaledInterval(interval)
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
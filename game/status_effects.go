@@ -0,0 +1,253 @@
+package game
+
+import (
+	"math"
+	"slices"
+)
+
+// StatusEffectKind identifica un efecto temporal sobre una unidad
+type StatusEffectKind string
+
+const (
+	EffectSlow   StatusEffectKind = "slow"   // Aumenta los intervalos de movimiento y ataque
+	EffectStun   StatusEffectKind = "stun"   // No puede moverse ni atacar
+	EffectBurn   StatusEffectKind = "burn"   // Daño periódico
+	EffectShield StatusEffectKind = "shield" // Absorbe daño entrante
+	EffectRally  StatusEffectKind = "rally"  // Acorta los intervalos y aumenta el daño
+	EffectReveal StatusEffectKind = "reveal" // Expuesta: puede ser objetivo aunque no lo sea normalmente
+)
+
+// StackingRule define qué pasa al aplicar un efecto que la unidad ya tiene
+type StackingRule string
+
+const (
+	StackRefresh     StackingRule = "refresh"     // Una instancia: reinicia duración y conserva la mayor magnitud
+	StackAdd         StackingRule = "stack"       // Una instancia: suma un stack (hasta MaxStacks) y reinicia duración
+	StackIndependent StackingRule = "independent" // Cada aplicación es una instancia separada
+)
+
+// StatusEffect es un modificador temporal aplicado a una unidad.
+// El significado de Magnitude depende del tipo:
+//   - slow: fracción extra de intervalo por stack (0.5 = +50%)
+//   - burn: daño por período por stack
+//   - shield: puntos de daño que absorbe (se consumen al recibir daño)
+//   - rally: fracción de intervalo que se reduce y de daño que se suma (0.25 = -25% / +25%)
+//   - stun, reveal: no usan magnitud
+type StatusEffect struct {
+	Kind           StatusEffectKind `json:"kind"`
+	SourceID       int              `json:"sourceId"` // Unidad (o carta) que lo aplicó; 0 si no aplica
+	RemainingTicks int              `json:"remainingTicks"`
+	Magnitude      float64          `json:"magnitude"`
+	Stacks         int              `json:"stacks"`
+	NextTick       int              `json:"-"` // Próximo tick del callback periódico
+}
+
+// statusEffectDef define las reglas de cada tipo de efecto
+type statusEffectDef struct {
+	Stacking  StackingRule
+	MaxStacks int
+	Period    int // Ticks entre callbacks periódicos (0 = sin callback)
+	OnTick    func(g *GameState, unit *UnitState, effect *StatusEffect)
+}
+
+var statusEffectDefs = map[StatusEffectKind]statusEffectDef{
+	EffectSlow:   {Stacking: StackAdd, MaxStacks: 3},
+	EffectStun:   {Stacking: StackRefresh, MaxStacks: 1},
+	EffectShield: {Stacking: StackRefresh, MaxStacks: 1},
+	EffectRally:  {Stacking: StackRefresh, MaxStacks: 1},
+	EffectReveal: {Stacking: StackRefresh, MaxStacks: 1},
+	EffectBurn: {
+		Stacking:  StackIndependent,
+		MaxStacks: 1,
+		Period:    5, // ~1 segundo
+		OnTick: func(g *GameState, unit *UnitState, effect *StatusEffect) {
+			damage := int(math.Round(effect.Magnitude * float64(effect.Stacks)))
+			damage = g.applyDamageLocked(unit, damage)
+			if source, ok := g.Units[effect.SourceID]; ok {
+				g.Stats.recordDamage(source.PlayerID, source.UnitType, unit, damage)
+			} else {
+				g.Stats.recordDamage(0, "", unit, damage)
+			}
+		},
+	},
+}
+
+// ApplyStatusEffect aplica un efecto a una unidad respetando las reglas de stacking.
+// Retorna false si la unidad no existe o el efecto no es válido.
+func (g *GameState) ApplyStatusEffect(unitID int, effect StatusEffect) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	unit, ok := g.Units[unitID]
+	if !ok || unit.HP <= 0 {
+		return false
+	}
+	return g.applyStatusEffectLocked(unit, effect)
+}
+
+// applyStatusEffectLocked aplica un efecto (requiere lock tomado)
+func (g *GameState) applyStatusEffectLocked(unit *UnitState, effect StatusEffect) bool {
+	def, ok := statusEffectDefs[effect.Kind]
+	if !ok || effect.RemainingTicks <= 0 {
+		return false
+	}
+	if effect.Stacks <= 0 {
+		effect.Stacks = 1
+	}
+	if def.Period > 0 {
+		effect.NextTick = g.Tick + def.Period
+	}
+
+	if def.Stacking != StackIndependent {
+		for i := range unit.Effects {
+			existing := &unit.Effects[i]
+			if existing.Kind != effect.Kind {
+				continue
+			}
+			if effect.RemainingTicks > existing.RemainingTicks {
+				existing.RemainingTicks = effect.RemainingTicks
+			}
+			existing.SourceID = effect.SourceID
+			switch def.Stacking {
+			case StackAdd:
+				existing.Stacks += effect.Stacks
+				if def.MaxStacks > 0 && existing.Stacks > def.MaxStacks {
+					existing.Stacks = def.MaxStacks
+				}
+			case StackRefresh:
+				if effect.Magnitude > existing.Magnitude {
+					existing.Magnitude = effect.Magnitude
+				}
+			}
+			return true
+		}
+	}
+
+	if def.MaxStacks > 0 && effect.Stacks > def.MaxStacks {
+		effect.Stacks = def.MaxStacks
+	}
+	unit.Effects = append(unit.Effects, effect)
+	return true
+}
+
+// hasEffect indica si la unidad tiene un efecto activo de un tipo
+func (u *UnitState) hasEffect(kind StatusEffectKind) bool {
+	for _, e := range u.Effects {
+		if e.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// intervalMultiplier retorna el factor aplicado a los intervalos de movimiento y ataque
+func (u *UnitState) intervalMultiplier() float64 {
+	mult := 1.0
+	for _, e := range u.Effects {
+		switch e.Kind {
+		case EffectSlow:
+			mult += e.Magnitude * float64(e.Stacks)
+		case EffectRally:
+			mult -= e.Magnitude
+		}
+	}
+	return math.Max(mult, 0.1)
+}
+
+// damageMultiplier retorna el factor aplicado al daño que inflige la unidad (efectos y veteranía)
+func (u *UnitState) damageMultiplier() float64 {
+	mult := 1.0 + u.RankDamageBonus
+	for _, e := range u.Effects {
+		if e.Kind == EffectRally {
+			mult += e.Magnitude
+		}
+	}
+	return mult
+}
+
+// targetable indica si la unidad puede ser objetivo de ataques (reveal la expone)
+func (u *UnitState) targetable() bool {
+	return u.IsTargetable || u.hasEffect(EffectReveal)
+}
+
+// scaledInterval aplica los efectos activos a un intervalo base en ticks
+func (u *UnitState) scaledInterval(base int) int {
+	scaled := int(math.Round(float64(base) * u.intervalMultiplier()))
+	if scaled < 1 {
+		scaled = 1
+	}
+	return scaled
+}
+
+// applyDamageLocked descuenta daño del HP de la unidad, consumiendo primero los escudos.
+// Retorna el daño que llegó al HP (requiere lock tomado).
+func (g *GameState) applyDamageLocked(unit *UnitState, damage int) int {
+	if damage <= 0 {
+		return 0
+	}
+	for i := range unit.Effects {
+		shield := &unit.Effects[i]
+		if shield.Kind != EffectShield || shield.Magnitude <= 0 {
+			continue
+		}
+		absorbed := math.Min(shield.Magnitude, float64(damage))
+		shield.Magnitude -= absorbed
+		damage -= int(absorbed)
+		if damage <= 0 {
+			break
+		}
+	}
+	unit.HP -= damage
+	return damage
+}
+
+// Effects avanza los efectos de estado: descuenta duraciones, elimina los efectos vencidos,
+// ejecuta los callbacks periódicos y quita los escudos agotados. El daño periódico corre sobre
+// la lista ya filtrada (applyDamageLocked la recorre), así cada escudo absorbe una sola vez.
+func (s *GameSimulation) Effects() {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	currentTick := s.state.Tick
+	for _, unit := range s.state.sortedUnitsLocked() {
+		if len(unit.Effects) == 0 {
+			continue
+		}
+		kept := make([]StatusEffect, 0, len(unit.Effects))
+		var pulses []StatusEffect
+		for _, effect := range unit.Effects {
+			def := statusEffectDefs[effect.Kind]
+			if def.OnTick != nil && def.Period > 0 && currentTick >= effect.NextTick {
+				pulses = append(pulses, effect)
+				effect.NextTick = currentTick + def.Period
+			}
+			effect.RemainingTicks--
+			if effect.RemainingTicks > 0 {
+				kept = append(kept, effect)
+			}
+		}
+		unit.Effects = kept
+
+		for i := range pulses {
+			if unit.HP <= 0 {
+				break
+			}
+			statusEffectDefs[pulses[i].Kind].OnTick(s.state, unit, &pulses[i])
+		}
+
+		unit.Effects = slices.DeleteFunc(unit.Effects, func(effect StatusEffect) bool {
+			return effect.Kind == EffectShield && effect.Magnitude <= 0
+		})
+		if len(unit.Effects) == 0 {
+			unit.Effects = nil
+		}
+	}
+}
+
+// copyEffects retorna una copia de los efectos (nil si no hay)
+func copyEffects(effects []StatusEffect) []StatusEffect {
+	if len(effects) == 0 {
+		return nil
+	}
+	return append([]StatusEffect(nil), effects...)
+}
@@ -0,0 +1,156 @@
+package game
+
+import "log/slog"
+
+// isHealer indica si la unidad tiene comportamiento de soporte (curación o reparación)
+func (u *UnitState) isHealer() bool {
+	return u.HealAmount > 0 && u.HealTargets != ""
+}
+
+// canHealLocked indica si healer puede curar a target: aliado vivo (propio o de un compañero de
+// equipo), de la categoría que atiende y con HP por debajo del máximo (requiere lock tomado)
+func (g *GameState) canHealLocked(healer, target *UnitState) bool {
+	if target.ID == healer.ID || !g.alliedLocked(target.PlayerID, healer.PlayerID) {
+		return false
+	}
+	if target.Category != healer.HealTargets {
+		return false
+	}
+	return target.HP > 0 && target.HP < target.MaxHP
+}
+
+// healLocked restaura HP a una unidad sin superar MaxHP y emite el evento.
+// Retorna el HP efectivamente restaurado (requiere lock tomado).
+func (g *GameState) healLocked(healerID int, target *UnitState, amount int) int {
+	if amount <= 0 || target.HP <= 0 || target.HP >= target.MaxHP {
+		return 0
+	}
+	if missing := target.MaxHP - target.HP; amount > missing {
+		amount = missing
+	}
+	target.HP += amount
+	g.emitEventLocked(EventHeal, HealEventData{
+		HealerID: healerID,
+		TargetID: target.ID,
+		PlayerID: target.PlayerID,
+		Amount:   amount,
+		HP:       target.HP,
+	})
+	return amount
+}
+
+// repairStructuresLocked repara todas las estructuras dañadas un porcentaje de su MaxHP
+// según Config.StructureRepairPercent (requiere lock tomado)
+func (g *GameState) repairStructuresLocked() {
+	percent := g.Config.StructureRepairPercent
+	if percent <= 0 {
+		return
+	}
+	for _, unit := range g.sortedUnitsLocked() {
+		if unit.Category != CategoryStructure || unit.HP <= 0 || unit.HP >= unit.MaxHP {
+			continue
+		}
+		amount := unit.MaxHP * percent / 100
+		if amount < 1 {
+			amount = 1
+		}
+		g.healLocked(0, unit, amount)
+	}
+}
+
+// updateHealerTargetLocked elige hacia dónde se mueve una unidad de soporte: el aliado
+// dañado más cercano dentro de su rango de detección o, si no hay, el aliado atendible
+// más cercano (para acompañarlo). Sin aliados, se queda en su lugar (requiere lock tomado).
+func (s *GameSimulation) updateHealerTargetLocked(unit *UnitState) {
+	var damaged, escort *UnitState
+	bestDamaged, bestEscort := 1_000_000, 1_000_000
+	for _, candidate := range s.state.sortedUnitsLocked() {
+		if candidate.ID == unit.ID || !s.state.alliedLocked(candidate.PlayerID, unit.PlayerID) || candidate.HP <= 0 {
+			continue
+		}
+		if candidate.Category != unit.HealTargets || candidate.isHealer() {
+			continue
+		}
+		dist := abs(unit.X-candidate.X) + abs(unit.Y-candidate.Y)
+		if s.state.canHealLocked(unit, candidate) && dist <= unit.DetectionRange && dist < bestDamaged {
+			bestDamaged = dist
+			damaged = candidate
+		}
+		if dist < bestEscort {
+			bestEscort = dist
+			escort = candidate
+		}
+	}
+
+	target := damaged
+	if target == nil {
+		target = escort
+	}
+	if target == nil {
+		unit.TargetX = unit.X
+		unit.TargetY = unit.Y
+		unit.TargetID = 0
+		return
+	}
+	unit.TargetX = target.X
+	unit.TargetY = target.Y
+	unit.TargetID = target.ID
+}
+
+// healerInRangeLocked indica si una unidad de soporte ya está a rango de curación de su
+// objetivo actual, en cuyo caso no necesita moverse (requiere lock tomado)
+func (s *GameSimulation) healerInRangeLocked(unit *UnitState) bool {
+	target, ok := s.state.Units[unit.TargetID]
+	if !ok || target.HP <= 0 || !s.state.alliedLocked(target.PlayerID, unit.PlayerID) {
+		return false
+	}
+	return abs(unit.X-target.X)+abs(unit.Y-target.Y) <= unit.HealRange
+}
+
+// Support procesa las curaciones y reparaciones de las unidades de soporte: cada una cura
+// al aliado más dañado (en proporción a su MaxHP) dentro de su rango, respetando su cooldown.
+func (s *GameSimulation) Support() {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	currentTick := s.state.Tick
+	for _, healer := range s.state.sortedUnitsLocked() {
+		if !healer.isHealer() || healer.HP <= 0 {
+			continue
+		}
+		if currentTick < healer.NextHealTick {
+			continue
+		}
+		if healer.hasEffect(EffectStun) {
+			continue
+		}
+
+		var target *UnitState
+		bestRatio := 2.0
+		for _, candidate := range s.state.sortedUnitsLocked() {
+			if !s.state.canHealLocked(healer, candidate) {
+				continue
+			}
+			if abs(healer.X-candidate.X)+abs(healer.Y-candidate.Y) > healer.HealRange {
+				continue
+			}
+			ratio := float64(candidate.HP) / float64(candidate.MaxHP)
+			if ratio < bestRatio || (ratio == bestRatio && candidate.ID < target.ID) {
+				bestRatio = ratio
+				target = candidate
+			}
+		}
+
+		if target == nil {
+			healer.HealTargetID = 0
+			continue
+		}
+
+		healed := s.state.healLocked(healer.ID, target, healer.HealAmount)
+		healer.HealTargetID = target.ID
+		healer.HealedTotal += healed
+		healer.Status = "healing"
+		healer.NextHealTick = currentTick + healer.scaledInterval(healer.HealIntervalTicks)
+		slog.Info("Heal", "tick", currentTick, "healerId", healer.ID, "targetId", target.ID, "amount", healed, "targetHP", target.HP)
+	}
+}
@@ -0,0 +1,136 @@
+package game
+
+import "errors"
+
+// TargetPriority define cómo elige objetivo una unidad entre los enemigos a su alcance
+type TargetPriority string
+
+const (
+	PriorityNearest    TargetPriority = "nearest"    // El más cercano (comportamiento por defecto)
+	PriorityLowestHP   TargetPriority = "lowest_hp"  // El de menos HP (rematar)
+	PriorityStructures TargetPriority = "structures" // Estructuras primero, luego el más cercano
+	PriorityGenerators TargetPriority = "generators" // Generadores primero, luego el más cercano
+	PriorityBase       TargetPriority = "base"       // Base principal primero, luego el más cercano
+)
+
+// preferredBy indica si el candidato pertenece a la clase que la política prioriza
+func (p TargetPriority) preferredBy(candidate *UnitState) bool {
+	switch p {
+	case PriorityStructures:
+		return candidate.Category == CategoryStructure
+	case PriorityGenerators:
+		return candidate.IsGenerator && candidate.UnitType != TypeMainBase
+	case PriorityBase:
+		return candidate.UnitType == TypeMainBase
+	default:
+		return false
+	}
+}
+
+// isValidEnemyTargetLocked indica si candidate puede ser objetivo de ataque de unit: vivo,
+// atacable y de un jugador que no es aliado (requiere lock tomado)
+func (g *GameState) isValidEnemyTargetLocked(unit, candidate *UnitState) bool {
+	return !g.alliedLocked(candidate.PlayerID, unit.PlayerID) && candidate.HP > 0 && candidate.targetable()
+}
+
+// selectTargetLocked elige el mejor objetivo enemigo a distancia Manhattan <= maxRange según la
+// política de la unidad. Desempata por distancia y luego por ID para que la elección no dependa
+// del orden de iteración del mapa (requiere lock tomado).
+func (g *GameState) selectTargetLocked(unit *UnitState, maxRange int) *UnitState {
+	return g.selectTargetWhereLocked(unit, maxRange, nil)
+}
+
+// selectTargetWhereLocked es selectTargetLocked restringido a los candidatos que acepta accept
+// (nil = todos) (requiere lock tomado)
+func (g *GameState) selectTargetWhereLocked(unit *UnitState, maxRange int, accept func(*UnitState) bool) *UnitState {
+	var best *UnitState
+	bestPreferred, bestHP, bestDist := false, 0, 0
+
+	for _, candidate := range g.sortedUnitsLocked() {
+		if !g.isValidEnemyTargetLocked(unit, candidate) {
+			continue
+		}
+		if accept != nil && !accept(candidate) {
+			continue
+		}
+		dist := abs(unit.X-candidate.X) + abs(unit.Y-candidate.Y)
+		if dist > maxRange {
+			continue
+		}
+		preferred := unit.TargetPriority.preferredBy(candidate)
+
+		better := best == nil
+		if !better && preferred != bestPreferred {
+			better = preferred
+		} else if !better {
+			if unit.TargetPriority == PriorityLowestHP && candidate.HP != bestHP {
+				better = candidate.HP < bestHP
+			} else if dist != bestDist {
+				better = dist < bestDist
+			} else {
+				better = candidate.ID < best.ID
+			}
+		}
+
+		if better {
+			best = candidate
+			bestPreferred, bestHP, bestDist = preferred, candidate.HP, dist
+		}
+	}
+	return best
+}
+
+// focusTargetLocked retorna el objetivo fijado por el jugador si sigue siendo válido.
+// Si murió o dejó de ser atacable, limpia el foco (requiere lock tomado).
+func (g *GameState) focusTargetLocked(unit *UnitState) *UnitState {
+	if unit.FocusTargetID == 0 {
+		return nil
+	}
+	target, ok := g.Units[unit.FocusTargetID]
+	if !ok || !g.isValidEnemyTargetLocked(unit, target) {
+		unit.FocusTargetID = 0
+		return nil
+	}
+	return target
+}
+
+// SetFocusTarget fija (o limpia con targetID = 0) el objetivo prioritario de una unidad o
+// estructura del jugador. El objetivo debe ser un enemigo atacable dentro del rango de
+// detección (unidades móviles) o de ataque (estructuras fijas).
+func (g *GameState) SetFocusTarget(playerID, unitID, targetID int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	unit, ok := g.Units[unitID]
+	if !ok {
+		return errors.New("unit not found")
+	}
+	if unit.PlayerID != playerID {
+		return errors.New("unit not owned by player")
+	}
+	if unit.AttackDamage <= 0 {
+		return errors.New("unit cannot attack")
+	}
+	if targetID == 0 {
+		unit.FocusTargetID = 0
+		return nil
+	}
+
+	target, ok := g.Units[targetID]
+	if !ok {
+		return errors.New("target not found")
+	}
+	if !g.isValidEnemyTargetLocked(unit, target) {
+		return errors.New("invalid focus target")
+	}
+	maxRange := unit.AttackRange
+	if unit.CanMove {
+		maxRange = unit.DetectionRange
+	}
+	if abs(unit.X-target.X)+abs(unit.Y-target.Y) > maxRange {
+		return errors.New("target out of range")
+	}
+
+	unit.FocusTargetID = targetID
+	return nil
+}
@@ -0,0 +1,231 @@
+package game
+
+import "sort"
+
+// Seat es un asiento de una partida por equipos: a qué equipo pertenece y si lo controla la IA.
+// El jugador de cada asiento recibe como ID la posición del asiento (1, 2, ...).
+type Seat struct {
+	Team int  `json:"team"`
+	AI   bool `json:"ai"`
+}
+
+// EndReasonTeamDestroyed es el motivo de fin cuando cayeron todas las bases de un equipo
+const EndReasonTeamDestroyed = "team_destroyed"
+
+// TwoVsTwoSeats retorna los asientos de un 2v2 con la cantidad de humanos indicada (0 a 4).
+// Los humanos ocupan primero el equipo 1 y luego el 2; el resto de los asientos son IA.
+func TwoVsTwoSeats(humans int) []Seat {
+	seats := []Seat{{Team: 1}, {Team: 1}, {Team: 2}, {Team: 2}}
+	for i := range seats {
+		seats[i].AI = i >= humans
+	}
+	return seats
+}
+
+// teamsEnabled indica si la partida usa asientos por equipos en lugar del 1v1 clásico
+func (c PhaseConfig) teamsEnabled() bool {
+	return len(c.Seats) > 0
+}
+
+// teamOfLocked retorna el equipo de un jugador. Sin equipo asignado cada jugador es su propio
+// equipo (requiere lock tomado)
+func (g *GameState) teamOfLocked(playerID int) int {
+	if p, ok := g.Players[playerID]; ok && p.Team > 0 {
+		return p.Team
+	}
+	return -playerID
+}
+
+// alliedLocked indica si dos jugadores son el mismo o están en el mismo equipo
+// (requiere lock tomado)
+func (g *GameState) alliedLocked(a, b int) bool {
+	return a == b || g.teamOfLocked(a) == g.teamOfLocked(b)
+}
+
+// buildsForLocked indica si las estructuras de owner extienden el área de construcción de
+// playerID: las propias siempre, las de aliados solo con SharedBuildArea (requiere lock tomado)
+func (g *GameState) buildsForLocked(owner, playerID int) bool {
+	if owner == playerID {
+		return true
+	}
+	return g.Config.SharedBuildArea && g.alliedLocked(owner, playerID)
+}
+
+// AreAllied indica si dos jugadores son aliados (o el mismo jugador)
+func (g *GameState) AreAllied(a, b int) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.alliedLocked(a, b)
+}
+
+// seatedPlayersLocked retorna los jugadores que disputan la partida ordenados por ID: los
+// asientos ocupados con equipos o el humano y la IA en el 1v1 clásico (requiere lock tomado)
+func (g *GameState) seatedPlayersLocked() []*Player {
+	players := make([]*Player, 0, len(g.Players))
+	if g.Config.teamsEnabled() {
+		for _, p := range g.Players {
+			if p.Team > 0 {
+				players = append(players, p)
+			}
+		}
+		sort.Slice(players, func(i, j int) bool { return players[i].ID < players[j].ID })
+		return players
+	}
+	for _, id := range []int{g.HumanPlayerID, g.AIPlayerID} {
+		if p, ok := g.Players[id]; ok {
+			players = append(players, p)
+		}
+	}
+	return players
+}
+
+// seatPlayerLocked ubica a un humano que se une en el primer asiento humano libre. Al unirse el
+// primero se crean también todos los jugadores IA. Retorna false si no quedan asientos humanos
+// (requiere lock tomado)
+func (g *GameState) seatPlayerLocked(player *Player) bool {
+	if len(g.Players) == 0 {
+		for i, seat := range g.Config.Seats {
+			if seat.AI {
+				g.addAIPlayerLocked(i+1, seat.Team)
+			}
+		}
+		g.nextPlayerID = len(g.Config.Seats) + 1
+	}
+	for i, seat := range g.Config.Seats {
+		if _, taken := g.Players[i+1]; taken || seat.AI {
+			continue
+		}
+		player.ID = i + 1
+		player.Team = seat.Team
+		if g.HumanPlayerID == 0 {
+			g.HumanPlayerID = player.ID
+		}
+		if g.AIPlayerID == 0 {
+			g.AIPlayerID = g.opposingAISeatLocked(seat.Team)
+		}
+		return true
+	}
+	return false
+}
+
+// opposingAISeatLocked retorna el primer jugador IA de un equipo distinto al indicado
+// (0 si no hay) (requiere lock tomado)
+func (g *GameState) opposingAISeatLocked(team int) int {
+	for i, seat := range g.Config.Seats {
+		if seat.AI && seat.Team != team {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// addAIPlayerLocked crea un jugador IA con defaultDeck y su mano inicial (requiere lock tomado)
+func (g *GameState) addAIPlayerLocked(id, team int) *Player {
+	aiPlayer := &Player{
+		ID:         id,
+		IsAI:       true,
+		Team:       team,
+		TimeBankMs: g.Config.TimeBankMs,
+	}
+	aiPlayer.Deck = defaultDeck()
+	shuffleCards(g.rng, aiPlayer.Deck)
+	aiPlayer.DeckCount = len(aiPlayer.Deck)
+
+	// Dibujar mano inicial para IA (cantidad según config)
+	for i := 0; i < g.Config.InitialCardsPerHand && len(aiPlayer.Deck) > 0 && !g.handFullLocked(aiPlayer); i++ {
+		g.drawCardLocked(aiPlayer) // drawCardLocked ya añade a Hand
+	}
+	g.HandUpdatedPlayers = append(g.HandUpdatedPlayers, aiPlayer.ID)
+
+	g.Players[aiPlayer.ID] = aiPlayer
+	return aiPlayer
+}
+
+// nearestEnemyBaseLocked retorna la base principal viva de un rival más cercana a la unidad.
+// placed indica si algún rival llegó a colocar base (para distinguir "todas caídas" de
+// "todavía sin bases") (requiere lock tomado)
+func (g *GameState) nearestEnemyBaseLocked(unit *UnitState) (base *UnitState, placed bool) {
+	bestDist := 0
+	for _, p := range g.seatedPlayersLocked() {
+		if g.alliedLocked(p.ID, unit.PlayerID) || p.BaseID == 0 {
+			continue
+		}
+		placed = true
+		candidate, ok := g.Units[p.BaseID]
+		if !ok || candidate.HP <= 0 {
+			continue
+		}
+		dist := abs(unit.X-candidate.X) + abs(unit.Y-candidate.Y)
+		if base == nil || dist < bestDist {
+			base, bestDist = candidate, dist
+		}
+	}
+	return base, placed
+}
+
+// baseDestroyedLocked indica si el jugador colocó su base y ya cayó (requiere lock tomado)
+func (g *GameState) baseDestroyedLocked(p *Player) bool {
+	if p.BaseID == 0 {
+		return false
+	}
+	base, ok := g.Units[p.BaseID]
+	return !ok || base.HP <= 0
+}
+
+// teamsEndLocked evalúa el fin de una partida por equipos: un equipo pierde cuando cayeron las
+// bases de todos sus jugadores (todas a la vez = empate). Ganador y perdedor son el jugador de
+// menor ID de cada equipo (requiere lock tomado)
+func (g *GameState) teamsEndLocked() *GameEndInfo {
+	alive := make(map[int]bool)
+	first := make(map[int]int)
+	teams := []int{}
+	for _, p := range g.seatedPlayersLocked() {
+		if _, seen := first[p.Team]; !seen {
+			first[p.Team] = p.ID
+			teams = append(teams, p.Team)
+		}
+		if !g.baseDestroyedLocked(p) {
+			alive[p.Team] = true
+		}
+	}
+
+	var survivors, fallen []int
+	for _, team := range teams {
+		if alive[team] {
+			survivors = append(survivors, team)
+		} else {
+			fallen = append(fallen, team)
+		}
+	}
+	switch {
+	case len(fallen) == 0:
+		return nil
+	case len(survivors) == 0:
+		return &GameEndInfo{Pending: true, Draw: true, Reason: EndReasonBothBasesDestroyed}
+	case len(survivors) == 1:
+		winner, loser := survivors[0], fallen[0]
+		return &GameEndInfo{
+			Pending:    true,
+			WinnerID:   first[winner],
+			LoserID:    first[loser],
+			WinnerTeam: winner,
+			LoserTeam:  loser,
+			Reason:     EndReasonTeamDestroyed,
+		}
+	}
+	return nil
+}
+
+// teamForfeitLocked arma el fin de juego cuando un jugador se rinde o abandona una partida por
+// equipos: pierde todo su equipo. LoserID es el jugador que abandonó (requiere lock tomado)
+func (g *GameState) teamForfeitLocked(loserID int, reason string) *GameEndInfo {
+	end := &GameEndInfo{Pending: true, LoserID: loserID, LoserTeam: g.teamOfLocked(loserID), Reason: reason}
+	for _, p := range g.seatedPlayersLocked() {
+		if p.Team != end.LoserTeam {
+			end.WinnerID, end.WinnerTeam = p.ID, p.Team
+			break
+		}
+	}
+	return end
+}
@@ -0,0 +1,95 @@
+package game
+
+import "log/slog"
+
+// TimeBankEventData es el payload de un evento "time_bank": tiempo restante autoritativo
+// del banco de un jugador durante la preparación
+type TimeBankEventData struct {
+	PlayerID    int  `json:"playerId"`
+	RemainingMs int  `json:"remainingMs"`
+	Running     bool `json:"running"`             // Se está descontando (jugador no listo)
+	Exhausted   bool `json:"exhausted,omitempty"` // Se agotó este tick: el jugador pasó a listo
+}
+
+// timeBanksEnabled indica si la partida usa bancos de tiempo
+func (c PhaseConfig) timeBanksEnabled() bool {
+	return c.TimeBankMs > 0
+}
+
+// tickMsLocked retorna la duración de un tick en milisegundos (requiere lock tomado)
+func (g *GameState) tickMsLocked() int {
+	tps := g.TicksPerSecond
+	if tps <= 0 {
+		tps = 5
+	}
+	return 1000 / tps
+}
+
+// isPlayerReadyLocked retorna el flag de listo del jugador (requiere lock tomado)
+func (g *GameState) isPlayerReadyLocked(playerID int) bool {
+	if p, ok := g.Players[playerID]; ok {
+		return p.Ready
+	}
+	return false
+}
+
+// emitTimeBankLocked emite el tiempo restante de un jugador (requiere lock tomado)
+func (g *GameState) emitTimeBankLocked(p *Player, exhausted bool) {
+	g.emitEventLocked(EventTimeBank, TimeBankEventData{
+		PlayerID:    p.ID,
+		RemainingMs: p.TimeBankMs,
+		Running:     !exhausted && !g.isPlayerReadyLocked(p.ID),
+		Exhausted:   exhausted,
+	})
+}
+
+// topUpTimeBanksLocked suma TimeBankIncrementMs al banco de cada humano al empezar la
+// preparación, sin superar TimeBankMaxMs (0 = sin tope), y emite el tiempo inicial (requiere
+// lock tomado). La IA no usa banco.
+func (g *GameState) topUpTimeBanksLocked() {
+	if !g.Config.timeBanksEnabled() {
+		return
+	}
+	for _, p := range g.sortedPlayersLocked() {
+		if p.IsAI {
+			continue
+		}
+		p.TimeBankMs += g.Config.TimeBankIncrementMs
+		if g.Config.TimeBankMaxMs > 0 && p.TimeBankMs > g.Config.TimeBankMaxMs {
+			p.TimeBankMs = g.Config.TimeBankMaxMs
+		}
+		g.emitTimeBankLocked(p, false)
+	}
+}
+
+// DrainTimeBanks descuenta un tick de preparación del banco de cada humano que no está listo.
+// Si un banco se agota, el jugador pasa a listo automáticamente. Emite el tiempo restante cada
+// segundo y al agotarse. El banco cuenta ticks (tickMsLocked por tick), no tiempo real: en pausa
+// el tick no avanza y un servidor atrasado descuenta lo mismo por tick.
+func (g *GameState) DrainTimeBanks() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.Config.timeBanksEnabled() || g.CurrentPhase != PhasePreparation {
+		return
+	}
+	tickMs := g.tickMsLocked()
+	report := (g.Tick-g.PhaseStartTick)%(1000/tickMs) == 0
+
+	for _, p := range g.sortedPlayersLocked() {
+		if p.IsAI || g.isPlayerReadyLocked(p.ID) {
+			continue
+		}
+		p.TimeBankMs -= tickMs
+		if p.TimeBankMs <= 0 {
+			p.TimeBankMs = 0
+			g.setReadyLocked(p.ID, true)
+			g.emitTimeBankLocked(p, true)
+			slog.Info("Time bank exhausted, player auto-ready", "tick", g.Tick, "playerId", p.ID)
+			continue
+		}
+		if report {
+			g.emitTimeBankLocked(p, false)
+		}
+	}
+}
@@ -27,6 +27,13 @@ const (
 	// Unidades navales (generadas por naval_generator)
 	TypeNavalShip = "naval_ship" // Barco básico
 
+	// Unidades de asedio (cartas)
+	TypeSiegeRam = "siege_ram" // Ariete: daño de asedio, bonus contra murallas
+
+	// Unidades de soporte (cartas)
+	TypeMedic    = "medic"    // Cura unidades terrestres aliadas
+	TypeEngineer = "engineer" // Repara estructuras aliadas
+
 	// Legacy
 	TypeWarrior = "warrior" // Warrior antiguo (deprecated)
 )
@@ -47,6 +54,14 @@ type UnitStats struct {
 	AttackIntervalTicks int     `json:"attackIntervalTicks"` // Ticks entre ataques
 	AttackDPS           float64 `json:"attackDps"`           // Daño por segundo (convierte a ticks)
 
+	// Damage type / armor class (ver DamageMatrix)
+	DamageType DamageType `json:"damageType,omitempty"` // Tipo de daño que inflige
+	ArmorClass ArmorClass `json:"armorClass"`           // Clase de armadura al recibir daño
+
+	// Splash: radio (Manhattan) alrededor del tile de impacto y curva de atenuación
+	SplashRadius  int           `json:"splashRadius"`            // 0 = solo el objetivo
+	SplashFalloff SplashFalloff `json:"splashFalloff,omitempty"` // none, linear, half
+
 	// Generator stats
 	IsGenerator        bool   `json:"isGenerator"`        // Si genera unidades
 	GeneratedUnitType  string `json:"generatedUnitType"`  // Tipo de unidad que genera
@@ -57,131 +72,205 @@ type UnitStats struct {
 	IsBlocker bool `json:"isBlocker"` // Si bloquea el paso
 
 	// Targeting
-	IsTargetable bool `json:"isTargetable"` // Si puede ser objetivo de ataques
+	IsTargetable   bool           `json:"isTargetable"`             // Si puede ser objetivo de ataques
+	TargetPriority TargetPriority `json:"targetPriority,omitempty"` // Política de elección de objetivo (vacío = nearest)
 
 	// Build Range - área que esta estructura expande para construcción
 	BuildRange int `json:"buildRange"` // Radio que extiende el área controlada (0 = no expande)
+
+	// Support stats (curación / reparación)
+	HealAmount        int          `json:"healAmount"`            // HP restaurado por aplicación (0 = no cura)
+	HealRange         int          `json:"healRange"`             // Rango de curación (Manhattan)
+	HealIntervalTicks int          `json:"healIntervalTicks"`     // Ticks entre curaciones
+	HealTargets       UnitCategory `json:"healTargets,omitempty"` // Categoría que puede curar (land_unit, structure)
+}
+
+// unitStats son las estadísticas base de cada tipo de unidad. Se arma una sola vez:
+// GetUnitStats se consulta por cada tile que evalúa el pathfinding.
+var unitStats = map[string]UnitStats{
+	// Base Principal
+	TypeMainBase: {
+		Category:           CategoryStructure,
+		HP:                 1000, // Alta vida
+		CanMove:            false,
+		DetectionRange:     5,
+		AttackDamage:       0, // No ataca
+		IsGenerator:        true,
+		GeneratedUnitType:  TypeWarrior,
+		GenerationInterval: 20, // Genera cada 4 segundos
+		MaxUnitsGenerated:  -1, // Infinitas unidades
+		IsBlocker:          true,
+		IsTargetable:       true, // Base puede ser atacada
+		BuildRange:         10,   // Área inicial de construcción
+		ArmorClass:         ArmorFortified,
+	},
+
+	// Torres
+	TypeTower: {
+		Category:            CategoryStructure,
+		HP:                  500,
+		CanMove:             false,
+		DetectionRange:      30, // Mayor que AttackRange para detectar enemigos
+		AttackDamage:        25,
+		AttackRange:         25, // 25 tiles de rango
+		AttackIntervalTicks: 10, // Ataca cada 2 segundos
+		AttackDPS:           12.5,
+		IsBlocker:           true,
+		IsTargetable:        true, // Torre puede ser atacada
+		BuildRange:          10,   // Extiende el área de construcción
+		DamageType:          DamagePiercing,
+		ArmorClass:          ArmorFortified,
+		TargetPriority:      PriorityLowestHP, // Remata unidades dañadas
+	},
+
+	// Generador de unidades terrestres
+	TypeLandGenerator: {
+		Category:           CategoryStructure,
+		HP:                 300,
+		CanMove:            false,
+		DetectionRange:     5,
+		IsGenerator:        true,
+		GeneratedUnitType:  TypeLandSoldier,
+		GenerationInterval: 25, // Genera cada 5 segundos
+		MaxUnitsGenerated:  -1, // Infinitas unidades
+		IsBlocker:          true,
+		IsTargetable:       true, // Generador puede ser atacado
+		BuildRange:         10,   // Extiende el área de construcción
+		ArmorClass:         ArmorFortified,
+	},
+
+	// Generador de unidades navales
+	TypeNavalGenerator: {
+		Category:           CategoryStructure,
+		HP:                 300,
+		CanMove:            false,
+		DetectionRange:     5,
+		IsGenerator:        true,
+		GeneratedUnitType:  TypeNavalShip,
+		GenerationInterval: 30, // Genera cada 6 segundos
+		MaxUnitsGenerated:  -1, // Infinitas unidades
+		IsBlocker:          true,
+		IsTargetable:       true, // Generador puede ser atacado
+		BuildRange:         10,   // Extiende el área de construcción
+		ArmorClass:         ArmorFortified,
+	},
+
+	// Muralla
+	TypeWall: {
+		Category:       CategoryStructure,
+		HP:             200,
+		CanMove:        false,
+		DetectionRange: 4,
+		IsBlocker:      true,
+		IsTargetable:   false, // No es objetivo normal: solo se ataca para abrir brecha (ver Block)
+		BuildRange:     10,    // Extiende menos el área
+		ArmorClass:     ArmorFortified,
+	},
+
+	// Soldado terrestre
+	TypeLandSoldier: {
+		Category:            CategoryLandUnit,
+		HP:                  100,
+		CanMove:             true,
+		MoveIntervalTicks:   5, // Se mueve cada segundo
+		DetectionRange:      10,
+		AttackDamage:        15,
+		AttackRange:         2, // Cuerpo a cuerpo
+		AttackIntervalTicks: 8, // Ataca cada 1.6 segundos
+		AttackDPS:           9.375,
+		IsTargetable:        true, // Soldado puede ser atacado
+		DamageType:          DamageMelee,
+		ArmorClass:          ArmorLight,
+		TargetPriority:      PriorityNearest,
+	},
+
+	// Barco naval
+	TypeNavalShip: {
+		Category:            CategoryNavalUnit,
+		HP:                  150,
+		CanMove:             true,
+		MoveIntervalTicks:   6, // Más lento que unidades terrestres
+		DetectionRange:      50,
+		AttackDamage:        20,
+		AttackRange:         15, // Rango naval
+		AttackIntervalTicks: 10, // Ataca cada 2 segundos
+		AttackDPS:           10,
+		IsTargetable:        true, // Barco puede ser atacado
+		DamageType:          DamageNaval,
+		ArmorClass:          ArmorHeavy,
+		SplashRadius:        1, // Bombardeo: daña también los tiles vecinos al impacto
+		SplashFalloff:       FalloffLinear,
+		TargetPriority:      PriorityStructures, // Bombardeo costero
+	},
+
+	// Ariete de asedio: lento, rompe murallas y estructuras
+	TypeSiegeRam: {
+		Category:            CategoryLandUnit,
+		HP:                  160,
+		CanMove:             true,
+		MoveIntervalTicks:   8, // Más lento que el soldado
+		DetectionRange:      10,
+		AttackDamage:        30,
+		AttackRange:         1,
+		AttackIntervalTicks: 15, // Ataca cada 3 segundos
+		AttackDPS:           10,
+		IsTargetable:        true,
+		DamageType:          DamageSiege,
+		ArmorClass:          ArmorHeavy,
+		TargetPriority:      PriorityStructures,
+	},
+
+	// Médico: cura unidades terrestres aliadas
+	TypeMedic: {
+		Category:          CategoryLandUnit,
+		HP:                80,
+		CanMove:           true,
+		MoveIntervalTicks: 5,
+		DetectionRange:    10, // Radio para buscar aliados heridos
+		IsTargetable:      true,
+		ArmorClass:        ArmorLight,
+		HealAmount:        10,
+		HealRange:         3,
+		HealIntervalTicks: 10, // Cura cada 2 segundos
+		HealTargets:       CategoryLandUnit,
+	},
+
+	// Ingeniero: repara estructuras aliadas
+	TypeEngineer: {
+		Category:          CategoryLandUnit,
+		HP:                90,
+		CanMove:           true,
+		MoveIntervalTicks: 5,
+		DetectionRange:    15, // Radio para buscar estructuras dañadas
+		IsTargetable:      true,
+		ArmorClass:        ArmorLight,
+		HealAmount:        20,
+		HealRange:         2,
+		HealIntervalTicks: 10,
+		HealTargets:       CategoryStructure,
+	},
+
+	// Legacy warrior
+	TypeWarrior: {
+		Category:            CategoryLandUnit,
+		HP:                  100,
+		CanMove:             true,
+		MoveIntervalTicks:   5,
+		DetectionRange:      50,
+		AttackDamage:        10,
+		AttackRange:         2,
+		AttackIntervalTicks: 10,
+		AttackDPS:           5,
+		IsTargetable:        true, // Warrior puede ser atacado
+		DamageType:          DamageMelee,
+		ArmorClass:          ArmorLight,
+		TargetPriority:      PriorityNearest,
+	},
 }
 
 // GetUnitStats retorna las estadísticas para un tipo de unidad
 func GetUnitStats(unitType string) UnitStats {
-	stats := map[string]UnitStats{
-		// Base Principal
-		TypeMainBase: {
-			Category:           CategoryStructure,
-			HP:                 1000, // Alta vida
-			CanMove:            false,
-			DetectionRange:     5,
-			AttackDamage:       0, // No ataca
-			IsGenerator:        true,
-			GeneratedUnitType:  TypeWarrior,
-			GenerationInterval: 20, // Genera cada 4 segundos
-			MaxUnitsGenerated:  -1, // Infinitas unidades
-			IsBlocker:          true,
-			IsTargetable:       true, // Base puede ser atacada
-			BuildRange:         10,   // Área inicial de construcción
-		},
-
-		// Torres
-		TypeTower: {
-			Category:            CategoryStructure,
-			HP:                  500,
-			CanMove:             false,
-			DetectionRange:      30, // Mayor que AttackRange para detectar enemigos
-			AttackDamage:        25,
-			AttackRange:         25, // 25 tiles de rango
-			AttackIntervalTicks: 10, // Ataca cada 2 segundos
-			AttackDPS:           12.5,
-			IsBlocker:           true,
-			IsTargetable:        true, // Torre puede ser atacada
-			BuildRange:          10,   // Extiende el área de construcción
-		},
-
-		// Generador de unidades terrestres
-		TypeLandGenerator: {
-			Category:           CategoryStructure,
-			HP:                 300,
-			CanMove:            false,
-			DetectionRange:     5,
-			IsGenerator:        true,
-			GeneratedUnitType:  TypeLandSoldier,
-			GenerationInterval: 25, // Genera cada 5 segundos
-			MaxUnitsGenerated:  -1, // Infinitas unidades
-			IsBlocker:          true,
-			IsTargetable:       true, // Generador puede ser atacado
-			BuildRange:         10,   // Extiende el área de construcción
-		},
-
-		// Generador de unidades navales
-		TypeNavalGenerator: {
-			Category:           CategoryStructure,
-			HP:                 300,
-			CanMove:            false,
-			DetectionRange:     5,
-			IsGenerator:        true,
-			GeneratedUnitType:  TypeNavalShip,
-			GenerationInterval: 30, // Genera cada 6 segundos
-			MaxUnitsGenerated:  -1, // Infinitas unidades
-			IsBlocker:          true,
-			IsTargetable:       true, // Generador puede ser atacado
-			BuildRange:         10,   // Extiende el área de construcción
-		},
-
-		// Muralla
-		TypeWall: {
-			Category:       CategoryStructure,
-			HP:             200,
-			CanMove:        false,
-			DetectionRange: 4,
-			IsBlocker:      true,
-			IsTargetable:   false, // Muralla NO puede ser atacada (solo bloquea)
-			BuildRange:     10,    // Extiende menos el área
-		},
-
-		// Soldado terrestre
-		TypeLandSoldier: {
-			Category:            CategoryLandUnit,
-			HP:                  100,
-			CanMove:             true,
-			MoveIntervalTicks:   5, // Se mueve cada segundo
-			DetectionRange:      10,
-			AttackDamage:        15,
-			AttackRange:         2, // Cuerpo a cuerpo
-			AttackIntervalTicks: 8, // Ataca cada 1.6 segundos
-			AttackDPS:           9.375,
-			IsTargetable:        true, // Soldado puede ser atacado
-		},
-
-		// Barco naval
-		TypeNavalShip: {
-			Category:            CategoryNavalUnit,
-			HP:                  150,
-			CanMove:             true,
-			MoveIntervalTicks:   6, // Más lento que unidades terrestres
-			DetectionRange:      50,
-			AttackDamage:        20,
-			AttackRange:         15, // Rango naval
-			AttackIntervalTicks: 10, // Ataca cada 2 segundos
-			AttackDPS:           10,
-			IsTargetable:        true, // Barco puede ser atacado
-		},
-
-		// Legacy warrior
-		TypeWarrior: {
-			Category:            CategoryLandUnit,
-			HP:                  100,
-			CanMove:             true,
-			MoveIntervalTicks:   5,
-			DetectionRange:      50,
-			AttackDamage:        10,
-			AttackRange:         2,
-			AttackIntervalTicks: 10,
-			AttackDPS:           5,
-			IsTargetable:        true, // Warrior puede ser atacado
-		},
-	}
-
-	if s, ok := stats[unitType]; ok {
+	if s, ok := unitStats[unitType]; ok {
 		return s
 	}
 
@@ -191,5 +280,6 @@ func GetUnitStats(unitType string) UnitStats {
 		HP:                100,
 		CanMove:           true,
 		MoveIntervalTicks: 5,
+		ArmorClass:        ArmorLight,
 	}
 }
@@ -9,6 +9,7 @@ type UpdateMessage struct {
 	Map               *GameMap           `json:"map,omitempty"`
 	Spawned           []*UnitState       `json:"spawned,omitempty"`
 	Moved             []UnitMove         `json:"moved,omitempty"`
+	Updated           []UnitUpdate       `json:"updated,omitempty"`
 	Dead              []int              `json:"dead,omitempty"`
 	CurrentPhase      GamePhase          `json:"currentPhase"`
 	TurnNumber        int                `json:"turnNumber"`
@@ -19,6 +20,10 @@ type UpdateMessage struct {
 	Config            PhaseConfig        `json:"config"` // Configuración de fases
 	CurrentPlayerTurn int                `json:"currentPlayerTurn"`
 	GameEnd           *GameEndInfo       `json:"gameEnd,omitempty"`
+	Pause             *PauseState        `json:"pause,omitempty"`
+	SuddenDeath       bool               `json:"suddenDeath,omitempty"`
+	Waves             *WaveState         `json:"waves,omitempty"`
+	ControlPoints     []ControlPoint     `json:"controlPoints,omitempty"`
 }
 
 // PhaseChangeEvent notifica cuando cambia la fase del juego
@@ -48,6 +53,10 @@ func SnapshotToUpdate(s Snapshot) UpdateMessage {
 		Config:            s.Config,
 		CurrentPlayerTurn: s.CurrentPlayerTurn,
 		GameEnd:           s.GameEnd,
+		Pause:             s.Pause,
+		SuddenDeath:       s.SuddenDeath,
+		Waves:             s.Waves,
+		ControlPoints:     s.ControlPoints,
 	}
 }
 
@@ -57,6 +66,7 @@ func DeltaToUpdate(d Delta) UpdateMessage {
 		Tick:             d.Tick,
 		Spawned:          d.Spawned,
 		Moved:            d.Moved,
+		Updated:          d.Updated,
 		Dead:             d.Dead,
 		CurrentPhase:     d.CurrentPhase,
 		TurnNumber:       d.TurnNumber,
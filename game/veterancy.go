@@ -0,0 +1,105 @@
+package game
+
+import (
+	"log/slog"
+	"math"
+)
+
+// VeterancyRank define un rango de veteranía. Se alcanza con Kills muertes o DamageDealt de daño
+// infligido (lo que ocurra primero; 0 = ese criterio no aplica). Los bonus son totales del rango,
+// no se acumulan con los de rangos anteriores.
+type VeterancyRank struct {
+	Kills            int     `json:"kills"`
+	DamageDealt      int     `json:"damageDealt"`
+	HPBonus          float64 `json:"hpBonus"`          // +% de HP máximo (la unidad gana la diferencia)
+	DamageBonus      float64 `json:"damageBonus"`      // +% de daño de ataque
+	AttackSpeedBonus float64 `json:"attackSpeedBonus"` // -% del intervalo entre ataques
+}
+
+// DefaultVeterancyRanks retorna los rangos por defecto (1 a 3)
+func DefaultVeterancyRanks() []VeterancyRank {
+	return []VeterancyRank{
+		{Kills: 2, DamageDealt: 150, HPBonus: 0.1, DamageBonus: 0.1},
+		{Kills: 5, DamageDealt: 400, HPBonus: 0.2, DamageBonus: 0.2, AttackSpeedBonus: 0.1},
+		{Kills: 10, DamageDealt: 1000, HPBonus: 0.3, DamageBonus: 0.3, AttackSpeedBonus: 0.2},
+	}
+}
+
+// UnitPromotedEventData es el payload de un evento "unit_promoted"
+type UnitPromotedEventData struct {
+	UnitID   int `json:"unitId"`
+	PlayerID int `json:"playerId"`
+	Rank     int `json:"rank"`
+	MaxHP    int `json:"maxHp"`
+	HP       int `json:"hp"`
+}
+
+// reached indica si una unidad con esas muertes y daño alcanza el rango
+func (r VeterancyRank) reached(kills, damage int) bool {
+	return (r.Kills > 0 && kills >= r.Kills) || (r.DamageDealt > 0 && damage >= r.DamageDealt)
+}
+
+// veteranAttackInterval aplica el bonus de velocidad de ataque del rango al intervalo base
+func (u *UnitState) veteranAttackInterval() int {
+	if u.RankAttackSpeedBonus <= 0 {
+		return u.AttackIntervalTicks
+	}
+	return int(math.Max(1, math.Round(float64(u.AttackIntervalTicks)*(1-u.RankAttackSpeedBonus))))
+}
+
+// creditDamageLocked suma daño infligido a un enemigo y promociona si corresponde
+// (requiere lock tomado)
+func (g *GameState) creditDamageLocked(attacker, victim *UnitState, damage int) {
+	if damage <= 0 || g.alliedLocked(attacker.PlayerID, victim.PlayerID) {
+		return
+	}
+	attacker.DamageDealt += damage
+	g.promoteLocked(attacker)
+}
+
+// creditKillLocked suma una muerte enemiga al atacante y promociona si corresponde
+// (requiere lock tomado)
+func (g *GameState) creditKillLocked(killer, victim *UnitState) {
+	if g.alliedLocked(killer.PlayerID, victim.PlayerID) {
+		return
+	}
+	killer.Kills++
+	g.promoteLocked(killer)
+}
+
+// promoteLocked sube la unidad al rango más alto que alcanzó según Config.Veterancy, aplica sus
+// bonus y emite "unit_promoted" (requiere lock tomado)
+func (g *GameState) promoteLocked(unit *UnitState) {
+	rank := unit.Rank
+	for i := unit.Rank; i < len(g.Config.Veterancy); i++ {
+		if !g.Config.Veterancy[i].reached(unit.Kills, unit.DamageDealt) {
+			break
+		}
+		rank = i + 1
+	}
+	if rank == unit.Rank {
+		return
+	}
+
+	def := g.Config.Veterancy[rank-1]
+	baseMaxHP := float64(unit.MaxHP) / (1 + unit.RankHPBonus)
+	maxHP := int(math.Round(baseMaxHP * (1 + def.HPBonus)))
+	if unit.HP > 0 {
+		unit.HP += maxHP - unit.MaxHP
+	}
+	unit.MaxHP = maxHP
+	unit.Rank = rank
+	unit.RankHPBonus = def.HPBonus
+	unit.RankDamageBonus = def.DamageBonus
+	unit.RankAttackSpeedBonus = def.AttackSpeedBonus
+
+	g.Stats.recordPromotion(unit)
+	g.emitEventLocked(EventUnitPromoted, UnitPromotedEventData{
+		UnitID:   unit.ID,
+		PlayerID: unit.PlayerID,
+		Rank:     rank,
+		MaxHP:    unit.MaxHP,
+		HP:       unit.HP,
+	})
+	slog.Info("Unit promoted", "tick", g.Tick, "unitId", unit.ID, "rank", rank, "kills", unit.Kills, "damageDealt", unit.DamageDealt)
+}
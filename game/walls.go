@@ -0,0 +1,392 @@
+package game
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+)
+
+// Conexiones de un segmento de muralla con sus vecinos cardinales del mismo jugador (bitmask)
+const (
+	WallConnectN = 1 << iota // Vecino en y-1
+	WallConnectE             // Vecino en x+1
+	WallConnectS             // Vecino en y+1
+	WallConnectW             // Vecino en x-1
+)
+
+// wallCrossCost es el costo de atravesar una muralla enemiga en la búsqueda de brecha:
+// cualquier camino libre, aunque sea largo, se prefiere a romper una muralla
+const wallCrossCost = 1000
+
+// WallBreachedEventData es el payload de un evento "wall_breached"
+type WallBreachedEventData struct {
+	WallID       int `json:"wallId"`
+	PlayerID     int `json:"playerId"` // Dueño de la muralla
+	X            int `json:"x"`
+	Y            int `json:"y"`
+	LineID       int `json:"lineId"`                 // Línea a la que pertenecía el segmento
+	BreachedByID int `json:"breachedById,omitempty"` // Última unidad que la dañó (0 si no se sabe)
+}
+
+// updateWallLinesLocked recalcula las conexiones entre segmentos de muralla: cada muralla
+// guarda la máscara de vecinos cardinales del mismo jugador y el ID de su línea (el menor ID
+// de los segmentos conectados) (requiere lock tomado).
+func (g *GameState) updateWallLinesLocked() {
+	walls := make(map[Point]*UnitState)
+	for _, unit := range g.Units {
+		if unit.UnitType == TypeWall && unit.HP > 0 {
+			walls[Point{X: unit.X, Y: unit.Y}] = unit
+		}
+	}
+
+	neighbours := []struct {
+		dx, dy int
+		mask   int
+	}{
+		{0, -1, WallConnectN},
+		{1, 0, WallConnectE},
+		{0, 1, WallConnectS},
+		{-1, 0, WallConnectW},
+	}
+
+	adjacent := func(wall *UnitState, fn func(other *UnitState, mask int)) {
+		for _, n := range neighbours {
+			other, ok := walls[Point{X: wall.X + n.dx, Y: wall.Y + n.dy}]
+			if ok && other.PlayerID == wall.PlayerID {
+				fn(other, n.mask)
+			}
+		}
+	}
+
+	for _, wall := range walls {
+		wall.WallMask = 0
+		wall.WallLineID = 0
+		adjacent(wall, func(_ *UnitState, mask int) { wall.WallMask |= mask })
+	}
+
+	// Componentes conexas: recorrer cada línea una vez y asignar el menor ID
+	for _, start := range walls {
+		if start.WallLineID != 0 {
+			continue
+		}
+		line := []*UnitState{start}
+		start.WallLineID = -1
+		lineID := start.ID
+		for i := 0; i < len(line); i++ {
+			if line[i].ID < lineID {
+				lineID = line[i].ID
+			}
+			adjacent(line[i], func(other *UnitState, _ int) {
+				if other.WallLineID == 0 {
+					other.WallLineID = -1
+					line = append(line, other)
+				}
+			})
+		}
+		for _, wall := range line {
+			wall.WallLineID = lineID
+		}
+	}
+}
+
+// breachTargetLocked retorna la muralla que la unidad está rompiendo si sigue en pie
+// (requiere lock tomado)
+func (g *GameState) breachTargetLocked(unit *UnitState) *UnitState {
+	if unit.BreachTargetID == 0 {
+		return nil
+	}
+	wall, ok := g.Units[unit.BreachTargetID]
+	if !ok || wall.HP <= 0 || g.alliedLocked(wall.PlayerID, unit.PlayerID) {
+		unit.BreachTargetID = 0
+		return nil
+	}
+	return wall
+}
+
+// applyBreachTargetLocked guarda el destino calculado por UpdateTargets como meta de la unidad
+// y, si está rompiendo una muralla, la reemplaza como objetivo inmediato (requiere lock tomado)
+func (s *GameSimulation) applyBreachTargetLocked(unit *UnitState) {
+	unit.GoalX, unit.GoalY = unit.TargetX, unit.TargetY
+	if wall := s.state.breachTargetLocked(unit); wall != nil {
+		unit.TargetX, unit.TargetY = wall.X, wall.Y
+		unit.TargetID = wall.ID
+	}
+}
+
+// blockGrid es una vista estática del mapa para la búsqueda de brechas: terreno, estructuras
+// fijas (que no se rompen) y murallas. Las unidades móviles se ignoran porque se mueven.
+type blockGrid struct {
+	state   *GameState
+	walls   map[int]*UnitState // índice de tile → muralla
+	blocked map[int]bool       // índice de tile → estructura fija que no es muralla
+}
+
+// breachFieldKey identifica un campo de distancias de brecha: la meta, el alcance con que se
+// llega a ella y lo que cambia qué tiles se pueden cruzar (naval o no, equipo dueño de murallas)
+type breachFieldKey struct {
+	goalX, goalY int
+	reach        int
+	breachCostKey
+}
+
+// breachCostKey identifica los costos de entrar a cada tile para un tipo de unidad
+type breachCostKey struct {
+	naval bool
+	team  int
+}
+
+// breachCache guarda los costos y campos de distancias calculados para una disposición de
+// murallas y estructuras; se vacía cuando esa disposición cambia
+type breachCache struct {
+	layout string
+	costs  map[breachCostKey][]int
+	fields map[breachFieldKey][]int
+}
+
+// maxBreachFields acota los campos guardados (las metas sobre unidades móviles cambian seguido)
+const maxBreachFields = 64
+
+func (b *blockGrid) index(x, y int) int {
+	return y*b.state.Map.Width + x
+}
+
+// layout describe la disposición de murallas y estructuras fijas: mientras no cambie, los
+// campos de distancias guardados siguen siendo válidos
+func (b *blockGrid) layout() string {
+	tiles := make([][2]int, 0, len(b.walls)+len(b.blocked))
+	for idx, wall := range b.walls {
+		tiles = append(tiles, [2]int{idx, wall.PlayerID})
+	}
+	for idx := range b.blocked {
+		tiles = append(tiles, [2]int{idx, 0})
+	}
+	sort.Slice(tiles, func(i, j int) bool { return tiles[i][0] < tiles[j][0] })
+	return fmt.Sprint(tiles)
+}
+
+// enterCosts calcula el costo de entrar a cada tile: 1, wallCrossCost si hay una muralla
+// enemiga o -1 si no se puede (terreno, estructura fija o muralla propia o aliada)
+func (b *blockGrid) enterCosts(key breachCostKey) []int {
+	width, height := b.state.Map.Width, b.state.Map.Height
+	costs := make([]int, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			idx := b.index(x, y)
+			tile := b.state.Map.Tiles[y][x]
+			switch {
+			case key.naval && tile.TerrainID != TerrainWater, !key.naval && !tile.Walkable, b.blocked[idx]:
+				costs[idx] = -1
+			case b.walls[idx] != nil:
+				if b.state.teamOfLocked(b.walls[idx].PlayerID) == key.team {
+					costs[idx] = -1
+				} else {
+					costs[idx] = wallCrossCost
+				}
+			default:
+				costs[idx] = 1
+			}
+		}
+	}
+	return costs
+}
+
+// breachField calcula, desde la meta hacia afuera, el costo mínimo de cada tile hasta quedar a
+// rango de ataque de la meta pudiendo atravesar murallas enemigas con un costo alto (-1 si no
+// hay camino). Todas las unidades con la misma clave comparten el campo.
+func (b *blockGrid) breachField(key breachFieldKey, costs []int) []int {
+	width, height := b.state.Map.Width, b.state.Map.Height
+	dist := make([]int, width*height)
+	for i := range dist {
+		dist[i] = -1
+	}
+
+	// Solo hay dos costos (un paso o cruzar una muralla): con una cola FIFO por costo cada una
+	// queda ordenada por distancia y basta con tomar la menor de las dos cabezas
+	var steps, crossings breachQueue
+	for y := max(key.goalY-key.reach, 0); y <= min(key.goalY+key.reach, height-1); y++ {
+		for x := max(key.goalX-key.reach, 0); x <= min(key.goalX+key.reach, width-1); x++ {
+			idx := b.index(x, y)
+			if abs(x-key.goalX)+abs(y-key.goalY) > key.reach || costs[idx] < 0 {
+				continue
+			}
+			dist[idx] = 0
+			steps.push(breachNode{idx: idx})
+		}
+	}
+
+	for !steps.empty() || !crossings.empty() {
+		var current breachNode
+		if crossings.empty() || (!steps.empty() && steps.head().dist <= crossings.head().dist) {
+			current = steps.pop()
+		} else {
+			current = crossings.pop()
+		}
+		if current.dist > dist[current.idx] {
+			continue // Entrada obsoleta
+		}
+		// Entrar al tile actual cuesta lo mismo desde cualquier vecino
+		cost := costs[current.idx]
+		nDist := current.dist + cost
+		for _, nIdx := range b.neighbours(current.idx) {
+			if costs[nIdx] < 0 || (dist[nIdx] != -1 && dist[nIdx] <= nDist) {
+				continue
+			}
+			dist[nIdx] = nDist
+			if cost == wallCrossCost {
+				crossings.push(breachNode{dist: nDist, idx: nIdx})
+			} else {
+				steps.push(breachNode{dist: nDist, idx: nIdx})
+			}
+		}
+	}
+	return dist
+}
+
+// neighbours retorna los índices de los tiles cardinales vecinos dentro del mapa
+func (b *blockGrid) neighbours(idx int) []int {
+	width, height := b.state.Map.Width, b.state.Map.Height
+	x, y := idx%width, idx/width
+	out := make([]int, 0, 4)
+	if y+1 < height {
+		out = append(out, idx+width)
+	}
+	if y > 0 {
+		out = append(out, idx-width)
+	}
+	if x+1 < width {
+		out = append(out, idx+1)
+	}
+	if x > 0 {
+		out = append(out, idx-1)
+	}
+	return out
+}
+
+// sealingWall sigue el campo de distancias desde la unidad hasta la meta. Si el mejor camino
+// cruza alguna muralla enemiga retorna la primera; nil si hay camino libre o no hay camino.
+func (b *blockGrid) sealingWall(unit *UnitState, field, costs []int) *UnitState {
+	idx := b.index(unit.X, unit.Y)
+	// Con camino libre el costo es la cantidad de pasos: menor que cruzar una sola muralla
+	if field[idx] < wallCrossCost {
+		return nil
+	}
+
+	for field[idx] > 0 {
+		next := -1
+		for _, nIdx := range b.neighbours(idx) {
+			if costs[nIdx] >= 0 && field[nIdx] >= 0 && field[nIdx]+costs[nIdx] == field[idx] {
+				next = nIdx
+				break
+			}
+		}
+		if next == -1 {
+			return nil
+		}
+		if wall, ok := b.walls[next]; ok {
+			return wall
+		}
+		idx = next
+	}
+	return nil
+}
+
+// breachNode es una entrada de las colas de breachField
+type breachNode struct {
+	dist int
+	idx  int
+}
+
+// breachQueue es una cola FIFO de breachField
+type breachQueue struct {
+	nodes []breachNode
+	next  int
+}
+
+func (q *breachQueue) empty() bool       { return q.next == len(q.nodes) }
+func (q *breachQueue) head() breachNode  { return q.nodes[q.next] }
+func (q *breachQueue) push(n breachNode) { q.nodes = append(q.nodes, n) }
+
+func (q *breachQueue) pop() breachNode {
+	n := q.nodes[q.next]
+	q.next++
+	return n
+}
+
+// Block detecta unidades cuyo camino hacia su meta está sellado por murallas enemigas y les
+// asigna como objetivo la primera muralla del mejor camino para abrir una brecha. Cuando el
+// camino vuelve a estar libre (o la muralla cae), la unidad retoma su meta. Los campos de
+// distancias se guardan por meta mientras no cambien las murallas ni las estructuras.
+func (s *GameSimulation) Block() {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	grid := &blockGrid{
+		state:   s.state,
+		walls:   make(map[int]*UnitState),
+		blocked: make(map[int]bool),
+	}
+	for _, unit := range s.state.Units {
+		if unit.HP <= 0 || unit.CanMove || !unit.IsBlocker {
+			continue
+		}
+		if unit.UnitType == TypeWall {
+			grid.walls[grid.index(unit.X, unit.Y)] = unit
+		} else {
+			grid.blocked[grid.index(unit.X, unit.Y)] = true
+		}
+	}
+	if len(grid.walls) > 0 {
+		if layout := grid.layout(); layout != s.breach.layout || len(s.breach.fields) > maxBreachFields {
+			s.breach = breachCache{
+				layout: layout,
+				costs:  make(map[breachCostKey][]int),
+				fields: make(map[breachFieldKey][]int),
+			}
+		}
+	}
+
+	for _, unit := range s.state.sortedUnitsLocked() {
+		if !unit.CanMove || unit.AttackDamage <= 0 || unit.HP <= 0 || unit.Stance == StanceHold {
+			continue
+		}
+		if len(grid.walls) == 0 || (unit.GoalX == unit.X && unit.GoalY == unit.Y) {
+			s.clearBreachLocked(unit)
+			continue
+		}
+
+		costKey := breachCostKey{naval: unit.Category == CategoryNavalUnit, team: s.state.teamOfLocked(unit.PlayerID)}
+		costs, ok := s.breach.costs[costKey]
+		if !ok {
+			costs = grid.enterCosts(costKey)
+			s.breach.costs[costKey] = costs
+		}
+		key := breachFieldKey{goalX: unit.GoalX, goalY: unit.GoalY, reach: max(unit.AttackRange, 1), breachCostKey: costKey}
+		field, ok := s.breach.fields[key]
+		if !ok {
+			field = grid.breachField(key, costs)
+			s.breach.fields[key] = field
+		}
+
+		wall := grid.sealingWall(unit, field, costs)
+		if wall == nil {
+			s.clearBreachLocked(unit)
+			continue
+		}
+		if unit.BreachTargetID != wall.ID {
+			slog.Info("Path sealed by wall", "tick", s.state.Tick, "unitId", unit.ID, "wallId", wall.ID)
+		}
+		unit.BreachTargetID = wall.ID
+		unit.TargetX, unit.TargetY = wall.X, wall.Y
+		unit.TargetID = wall.ID
+	}
+}
+
+// clearBreachLocked deja de romper murallas y devuelve la unidad a su meta (requiere lock tomado)
+func (s *GameSimulation) clearBreachLocked(unit *UnitState) {
+	if unit.BreachTargetID == 0 {
+		return
+	}
+	unit.BreachTargetID = 0
+	unit.TargetX, unit.TargetY = unit.GoalX, unit.GoalY
+	unit.TargetID = 0
+}
@@ -0,0 +1,225 @@
+package game
+
+import "log/slog"
+
+// GameMode define contra quién se juega la partida
+type GameMode string
+
+const (
+	GameModeVersus GameMode = "versus" // Contra la IA o PvP (por defecto)
+	GameModeWaves  GameMode = "waves"  // PvE: el jugador defiende su base de oleadas programadas
+)
+
+// EndReasonWavesSurvived es el motivo de victoria al derrotar la última oleada
+const EndReasonWavesSurvived = "waves_survived"
+
+// WaveGroup es un grupo de unidades de un mismo tipo dentro de una oleada
+type WaveGroup struct {
+	UnitType string `json:"unitType"`
+	Count    int    `json:"count"`
+}
+
+// WaveDef define una oleada: sus grupos salen en orden, una unidad cada SpawnInterval ticks
+type WaveDef struct {
+	Groups        []WaveGroup `json:"groups"`
+	SpawnInterval int         `json:"spawnInterval"` // Ticks entre unidades (0 = todas juntas)
+}
+
+// WaveSpawnPoint es un punto de aparición de las oleadas
+type WaveSpawnPoint struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// DefaultWaves retorna las oleadas por defecto: crecen en tamaño y suman tipos de unidad
+func DefaultWaves() []WaveDef {
+	return []WaveDef{
+		{SpawnInterval: 5, Groups: []WaveGroup{{TypeLandSoldier, 3}}},
+		{SpawnInterval: 5, Groups: []WaveGroup{{TypeLandSoldier, 5}}},
+		{SpawnInterval: 5, Groups: []WaveGroup{{TypeLandSoldier, 5}, {TypeWarrior, 2}}},
+		{SpawnInterval: 5, Groups: []WaveGroup{{TypeLandSoldier, 6}, {TypeWarrior, 3}}},
+		{SpawnInterval: 4, Groups: []WaveGroup{{TypeSiegeRam, 1}, {TypeLandSoldier, 6}, {TypeWarrior, 3}}},
+		{SpawnInterval: 4, Groups: []WaveGroup{{TypeSiegeRam, 2}, {TypeLandSoldier, 8}, {TypeWarrior, 4}}},
+		{SpawnInterval: 3, Groups: []WaveGroup{{TypeSiegeRam, 2}, {TypeLandSoldier, 8}, {TypeWarrior, 5}, {TypeMedic, 1}}},
+		{SpawnInterval: 3, Groups: []WaveGroup{{TypeSiegeRam, 3}, {TypeLandSoldier, 10}, {TypeWarrior, 6}, {TypeMedic, 2}}},
+	}
+}
+
+// WaveState es el progreso de las oleadas (solo en modo waves)
+type WaveState struct {
+	Current     int              `json:"current"` // Oleada en curso (0 = aún no empezó ninguna)
+	Total       int              `json:"total"`
+	Pending     int              `json:"pending"` // Unidades de la oleada en curso que faltan salir
+	Alive       int              `json:"alive"`   // Unidades de oleadas vivas
+	SpawnPoints []WaveSpawnPoint `json:"spawnPoints,omitempty"`
+
+	queue         []string
+	nextSpawnTick int
+	interval      int
+	nextPoint     int
+}
+
+// WaveStartedEventData es el payload de un evento "wave_started"
+type WaveStartedEventData struct {
+	Wave  int `json:"wave"`
+	Total int `json:"total"`
+	Units int `json:"units"`
+}
+
+// wavesEnabled indica si la partida es en modo oleadas
+func (c PhaseConfig) wavesEnabled() bool {
+	return c.Mode == GameModeWaves
+}
+
+// waveDefsLocked retorna las oleadas configuradas o las por defecto (requiere lock tomado)
+func (g *GameState) waveDefsLocked() []WaveDef {
+	if len(g.Config.Waves) > 0 {
+		return g.Config.Waves
+	}
+	return DefaultWaves()
+}
+
+// wavesLocked retorna (creando si hace falta) el estado de las oleadas; nil fuera del modo
+// waves (requiere lock tomado)
+func (g *GameState) wavesLocked() *WaveState {
+	if !g.Config.wavesEnabled() {
+		return nil
+	}
+	if g.Waves == nil {
+		g.Waves = &WaveState{Total: len(g.waveDefsLocked())}
+	}
+	return g.Waves
+}
+
+// waveUnitsAliveLocked cuenta las unidades vivas del atacante neutral (requiere lock tomado)
+func (g *GameState) waveUnitsAliveLocked() int {
+	n := 0
+	for _, unit := range g.Units {
+		if unit.PlayerID == g.AIPlayerID && unit.HP > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// waveSnapshotLocked retorna una copia del estado de las oleadas para el snapshot
+// (requiere lock tomado)
+func (g *GameState) waveSnapshotLocked() *WaveState {
+	waves := g.wavesLocked()
+	if waves == nil {
+		return nil
+	}
+	return &WaveState{
+		Current:     waves.Current,
+		Total:       waves.Total,
+		Pending:     len(waves.queue),
+		Alive:       g.waveUnitsAliveLocked(),
+		SpawnPoints: append([]WaveSpawnPoint(nil), waves.SpawnPoints...),
+	}
+}
+
+// defaultWaveSpawnPointsLocked elige puntos sobre el borde del mapa opuesto a la base del
+// jugador: el tile caminable más cercano al borde a 1/4, 1/2 y 3/4 de la altura
+// (requiere lock tomado)
+func (g *GameState) defaultWaveSpawnPointsLocked() []WaveSpawnPoint {
+	base := g.mainBaseLocked(g.HumanPlayerID)
+	if base == nil {
+		return nil
+	}
+	edge, step := g.Map.Width-1, -1
+	if base.X >= g.Map.Width/2 {
+		edge, step = 0, 1
+	}
+
+	var points []WaveSpawnPoint
+	for _, y := range []int{g.Map.Height / 4, g.Map.Height / 2, 3 * g.Map.Height / 4} {
+		for x := edge; x >= 0 && x < g.Map.Width; x += step {
+			if g.Map.IsWalkable(x, y) {
+				points = append(points, WaveSpawnPoint{X: x, Y: y})
+				break
+			}
+		}
+	}
+	return points
+}
+
+// startWaveLocked lanza la siguiente oleada al empezar la batalla (requiere lock tomado)
+func (g *GameState) startWaveLocked() {
+	waves := g.wavesLocked()
+	if waves == nil || waves.Current >= waves.Total {
+		return
+	}
+	if waves.SpawnPoints == nil {
+		waves.SpawnPoints = append([]WaveSpawnPoint(nil), g.Config.WaveSpawnPoints...)
+		if len(waves.SpawnPoints) == 0 {
+			waves.SpawnPoints = g.defaultWaveSpawnPointsLocked()
+		}
+	}
+
+	def := g.waveDefsLocked()[waves.Current]
+	waves.Current++
+	waves.queue = waves.queue[:0]
+	for _, group := range def.Groups {
+		for i := 0; i < group.Count; i++ {
+			waves.queue = append(waves.queue, group.UnitType)
+		}
+	}
+	waves.interval = def.SpawnInterval
+	waves.nextSpawnTick = g.Tick
+
+	g.emitEventLocked(EventWaveStarted, WaveStartedEventData{Wave: waves.Current, Total: waves.Total, Units: len(waves.queue)})
+	slog.Info("Wave started", "tick", g.Tick, "wave", waves.Current, "total", waves.Total, "units", len(waves.queue))
+}
+
+// spawnWaveUnitLocked coloca una unidad de oleada en el punto de aparición que toca o en un
+// tile libre a su alrededor (radio 2). Retorna false si no hay lugar (requiere lock tomado).
+func (g *GameState) spawnWaveUnitLocked(waves *WaveState, unitType string) bool {
+	for i := range waves.SpawnPoints {
+		point := waves.SpawnPoints[(waves.nextPoint+i)%len(waves.SpawnPoints)]
+		for radius := 0; radius <= 2; radius++ {
+			for dx := -radius; dx <= radius; dx++ {
+				for dy := -radius; dy <= radius; dy++ {
+					if abs(dx)+abs(dy) != radius {
+						continue
+					}
+					x, y := point.X+dx, point.Y+dy
+					if !g.canUnitTypeEnter(unitType, -1, x, y) {
+						continue
+					}
+					g.createUnitLocked(g.AIPlayerID, unitType, x, y, 0)
+					waves.nextPoint = (waves.nextPoint + i + 1) % len(waves.SpawnPoints)
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// SpawnWaves hace salir las unidades pendientes de la oleada en curso según su intervalo
+func (s *GameSimulation) SpawnWaves() {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	g := s.state
+	waves := g.wavesLocked()
+	if waves == nil || len(waves.SpawnPoints) == 0 {
+		return
+	}
+	for len(waves.queue) > 0 && g.Tick >= waves.nextSpawnTick {
+		if !g.spawnWaveUnitLocked(waves, waves.queue[0]) {
+			// Puntos ocupados: reintentar en el próximo tick
+			waves.nextSpawnTick = g.Tick + 1
+			return
+		}
+		waves.queue = waves.queue[1:]
+		waves.nextSpawnTick = g.Tick + waves.interval
+	}
+}
+
+// wavesSurvivedLocked indica si el jugador derrotó la última oleada (requiere lock tomado)
+func (g *GameState) wavesSurvivedLocked() bool {
+	waves := g.wavesLocked()
+	return waves != nil && waves.Total > 0 && waves.Current >= waves.Total &&
+		len(waves.queue) == 0 && g.waveUnitsAliveLocked() == 0
+}
@@ -23,7 +23,13 @@ func main() {
 	gameManager := game.NewGameManager()
 	wsHub := network.NewWsHub()
 
-	httpServer := network.NewHttpServer(gameManager, wsHub)
+	deckStore, err := NewDeckStore()
+	if err != nil {
+		slog.Error("No se pudo crear el almacenamiento de mazos", "error", err)
+		return
+	}
+
+	httpServer := network.NewHttpServer(gameManager, wsHub, deckStore)
 	go httpServer.Start()
 
 	lastSnapshots := make(map[int]*game.Snapshot)
@@ -49,12 +55,19 @@ func main() {
 						lastSnapshots[g.ID] = &currentSnapshot
 					}
 					gameManager.EndGame(g.ID, g.State.GameEnd.LoserID, g.State.GameEnd.Reason)
+					if result, ok := gameManager.GetMatchResult(g.ID); ok {
+						wsHub.Broadcast(g.ID, game.BuildGameEndedMessage(g.ID, result))
+					}
 					// Limpiar memoria de snapshots y otros recursos del juego terminado
 					delete(lastSnapshots, g.ID)
 					continue
 				}
-				// Si hay fin de juego pendiente, no avanzar simulación; solo emitir snapshot si cambió
+				// Si hay fin de juego pendiente, no avanzar simulación: solo aplicar confirm_end
+				// y emitir snapshot si cambió
 				if g.State.IsGameEndPending() {
+					g.Simulation.ProcessTick()
+					sendRejectedCommands(wsHub, g)
+					broadcastEvents(wsHub, g)
 					currentSnapshot := game.BuildSnapshot(g.State)
 					if last, ok := lastSnapshots[g.ID]; !ok || !reflect.DeepEqual(*last, currentSnapshot) {
 						wsHub.Broadcast(g.ID, game.SnapshotToUpdate(currentSnapshot))
@@ -67,11 +80,18 @@ func main() {
 				previousPhase := g.State.GetCurrentPhase()
 
 				g.Simulation.ProcessTick()
+				sendRejectedCommands(wsHub, g)
+
+				// Enviar eventos de combate del tick (ataques, impactos en área)
+				broadcastEvents(wsHub, g)
 
-				// Verificar condiciones de victoria/derrota
-				if gameOver, loserID, reason := g.Simulation.CheckVictoryConditions(); gameOver {
-					slog.Info("Game ended - victory condition met (pending confirmation)", "gameId", g.ID, "loserId", loserID, "reason", reason)
-					g.State.SetPendingEnd(loserID, reason)
+				// Verificar condiciones de victoria/derrota (una rendición ya deja el fin pendiente)
+				if g.State.IsGameEndPending() {
+					continue
+				}
+				if end := g.Simulation.CheckGameEnd(); end != nil {
+					slog.Info("Game ended - victory condition met (pending confirmation)", "gameId", g.ID, "loserId", end.LoserID, "draw", end.Draw, "reason", end.Reason)
+					g.State.SetPendingEndInfo(end)
 					currentSnapshot := game.BuildSnapshot(g.State)
 					if last, ok := lastSnapshots[g.ID]; !ok || !reflect.DeepEqual(*last, currentSnapshot) {
 						wsHub.Broadcast(g.ID, game.SnapshotToUpdate(currentSnapshot))
@@ -111,3 +131,26 @@ func main() {
 		<-ticker.C
 	}
 }
+
+// broadcastEvents envía los eventos emitidos desde el último drenado. Los eventos privados
+// (p.ej. cartas descartadas) solo van al dueño de la mano.
+func broadcastEvents(wsHub *network.WsHub, g *game.Game) {
+	events := g.State.DrainEvents()
+	if len(events) == 0 {
+		return
+	}
+	public, private := game.SplitPrivateEvents(events)
+	if len(public) > 0 {
+		wsHub.Broadcast(g.ID, game.BuildEventsMessage(g.State.Tick, public))
+	}
+	for playerID, playerEvents := range private {
+		wsHub.SendToPlayer(g.ID, playerID, game.BuildEventsMessage(g.State.Tick, playerEvents))
+	}
+}
+
+// sendRejectedCommands avisa a cada jugador los comandos suyos que la simulación rechazó este tick
+func sendRejectedCommands(wsHub *network.WsHub, g *game.Game) {
+	for _, rejected := range g.Simulation.DrainRejectedCommands() {
+		wsHub.SendToPlayer(g.ID, rejected.Command.PlayerID, game.BuildCommandRejectedMessage(rejected))
+	}
+}
@@ -0,0 +1,11 @@
+-- Create decks table (mazos armados por cada jugador)
+CREATE TABLE IF NOT EXISTS decks (
+    id SERIAL PRIMARY KEY,
+    owner VARCHAR(32) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    cards JSONB NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (owner, name)
+);
+
+CREATE INDEX IF NOT EXISTS decks_owner_idx ON decks (owner);
@@ -0,0 +1,94 @@
+package network
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"autobattle-server/game"
+)
+
+// playerKey identifica a un jugador dentro de una partida
+type playerKey struct {
+	gameID   int
+	playerID int
+}
+
+// disconnectTimers mantiene un único temporizador de desconexión por jugador. Reconectar lo
+// cancela y una nueva desconexión lo reemplaza, así una caída vieja nunca termina la partida.
+// mu también ordena las altas y bajas de conexión (ver playerConnected/playerDisconnected).
+type disconnectTimers struct {
+	mu     sync.Mutex
+	timers map[playerKey]*time.Timer
+}
+
+func newDisconnectTimers() *disconnectTimers {
+	return &disconnectTimers{timers: make(map[playerKey]*time.Timer)}
+}
+
+// startLocked (re)inicia el temporizador del jugador; onTimeout se ejecuta con mu tomado y solo
+// si sigue siendo el temporizador vigente al vencer. Requiere mu tomado.
+func (d *disconnectTimers) startLocked(key playerKey, timeout time.Duration, onTimeout func()) {
+	if t, ok := d.timers[key]; ok {
+		t.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(timeout, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if d.timers[key] != timer {
+			return
+		}
+		delete(d.timers, key)
+		onTimeout()
+	})
+	d.timers[key] = timer
+}
+
+// cancelLocked detiene el temporizador del jugador (si había uno pendiente). Requiere mu tomado.
+func (d *disconnectTimers) cancelLocked(key playerKey) {
+	if t, ok := d.timers[key]; ok {
+		t.Stop()
+		delete(d.timers, key)
+	}
+}
+
+// playerDisconnected marca al jugador como desconectado si no le queda otro cliente abierto e
+// inicia su temporizador: al vencer sin reconexión deja pendiente su derrota (con equipos, la de
+// todo su equipo) y el loop de ticks cierra la partida como con una rendición. Todo corre con el
+// lock de los temporizadores para que una reconexión no se intercale entre el chequeo y el alta.
+func (s *HttpServer) playerDisconnected(gameID, playerID int) {
+	g, ok := s.manager.GetGame(gameID)
+	if !ok {
+		return
+	}
+
+	s.disconnects.mu.Lock()
+	defer s.disconnects.mu.Unlock()
+
+	if s.wsHub.HasPlayer(gameID, playerID) {
+		return
+	}
+	g.State.SetPlayerConnected(playerID, false)
+	slog.Info("Player disconnected", "gameId", gameID, "playerId", playerID)
+
+	timeoutSeconds := g.State.Config.DisconnectTimeoutSeconds
+	s.disconnects.startLocked(playerKey{gameID, playerID}, time.Duration(timeoutSeconds)*time.Second, func() {
+		g, ok := s.manager.GetGame(gameID)
+		if !ok || s.wsHub.HasPlayer(gameID, playerID) || g.State.IsPlayerConnected(playerID) || g.State.IsGameEndPending() {
+			return
+		}
+		slog.Info("Disconnect timeout reached; game end pending", "gameId", gameID, "playerId", playerID, "timeoutSeconds", timeoutSeconds)
+		g.State.SetPendingEnd(playerID, "disconnect_timeout")
+	})
+}
+
+// playerConnected cancela el temporizador de desconexión y marca al jugador como conectado
+func (s *HttpServer) playerConnected(g *game.Game, playerID int) {
+	s.disconnects.mu.Lock()
+	defer s.disconnects.mu.Unlock()
+
+	s.disconnects.cancelLocked(playerKey{g.ID, playerID})
+	g.State.SetPlayerConnected(playerID, true)
+	slog.Info("Player connected", "gameId", g.ID, "playerId", playerID)
+}
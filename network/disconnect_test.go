@@ -0,0 +1,159 @@
+package network
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// start y cancel toman el lock como lo hacen playerDisconnected/playerConnected
+func start(d *disconnectTimers, key playerKey, timeout time.Duration, onTimeout func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.startLocked(key, timeout, onTimeout)
+}
+
+func cancel(d *disconnectTimers, key playerKey) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cancelLocked(key)
+}
+
+func TestDisconnectTimerFiresWithoutReconnect(t *testing.T) {
+	timers := newDisconnectTimers()
+	fired := make(chan struct{}, 1)
+
+	start(timers, playerKey{1, 1}, 10*time.Millisecond, func() { fired <- struct{}{} })
+
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("expected the disconnect timer to fire")
+	}
+}
+
+func TestDisconnectTimerCancelledOnReconnect(t *testing.T) {
+	timers := newDisconnectTimers()
+	var fired atomic.Int32
+
+	start(timers, playerKey{1, 1}, 20*time.Millisecond, func() { fired.Add(1) })
+	cancel(timers, playerKey{1, 1})
+
+	time.Sleep(60 * time.Millisecond)
+	if n := fired.Load(); n != 0 {
+		t.Errorf("expected a cancelled timer not to fire, fired %d times", n)
+	}
+}
+
+func TestStaleDisconnectTimerIsIgnored(t *testing.T) {
+	timers := newDisconnectTimers()
+	var stale, current atomic.Int32
+
+	// Desconexión, reconexión y nueva desconexión: solo cuenta el último temporizador
+	start(timers, playerKey{1, 1}, 20*time.Millisecond, func() { stale.Add(1) })
+	start(timers, playerKey{1, 1}, 60*time.Millisecond, func() { current.Add(1) })
+
+	time.Sleep(40 * time.Millisecond)
+	if n := stale.Load() + current.Load(); n != 0 {
+		t.Fatalf("expected no timeout before the newest timer expires, got %d", n)
+	}
+	time.Sleep(80 * time.Millisecond)
+	if stale.Load() != 0 || current.Load() != 1 {
+		t.Errorf("expected only the newest timer to fire once, stale=%d current=%d", stale.Load(), current.Load())
+	}
+}
+
+func TestStaleDisconnectTimerAlreadyRunningIsIgnored(t *testing.T) {
+	timers := newDisconnectTimers()
+	key := playerKey{1, 1}
+	var fired atomic.Int32
+
+	// El temporizador vence mientras el jugador reconecta y vuelve a caerse (lock tomado):
+	// cuando el callback obtiene el lock ya no es el vigente y no debe terminar la partida
+	start(timers, key, 10*time.Millisecond, func() { fired.Add(1) })
+	timers.mu.Lock()
+	time.Sleep(30 * time.Millisecond)
+	replacement := time.AfterFunc(time.Hour, func() {})
+	defer replacement.Stop()
+	timers.timers[key] = replacement
+	timers.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+	if n := fired.Load(); n != 0 {
+		t.Errorf("expected the superseded timer to be ignored, fired %d times", n)
+	}
+}
+
+func TestDisconnectTimersArePerPlayer(t *testing.T) {
+	timers := newDisconnectTimers()
+	fired := make(chan playerKey, 2)
+
+	start(timers, playerKey{1, 1}, 10*time.Millisecond, func() { fired <- playerKey{1, 1} })
+	start(timers, playerKey{1, 2}, 10*time.Millisecond, func() { fired <- playerKey{1, 2} })
+	cancel(timers, playerKey{1, 1})
+
+	select {
+	case key := <-fired:
+		if key != (playerKey{1, 2}) {
+			t.Errorf("expected only player 2's timer to fire, got %+v", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected player 2's timer to fire")
+	}
+}
+
+func TestDisconnectTimeoutLeavesEndPending(t *testing.T) {
+	s, _ := newTestServer(t)
+	g := s.manager.CreateGame()
+	player := g.State.AddPlayer()
+	g.State.Config.DisconnectTimeoutSeconds = 0
+
+	s.playerDisconnected(g.ID, player.ID)
+
+	eventually(t, "pending end", g.State.IsGameEndPending)
+	if g.State.GameEnd.Reason != "disconnect_timeout" || g.State.GameEnd.LoserID != player.ID {
+		t.Fatalf("expected a pending disconnect_timeout loss for player %d, got %+v", player.ID, g.State.GameEnd)
+	}
+	// El loop de ticks cierra la partida al confirmar el fin, no el temporizador
+	if _, ok := s.manager.GetGame(g.ID); !ok {
+		t.Fatal("expected the game to stay active until the end is confirmed")
+	}
+}
+
+func TestDisconnectTimeoutIgnoredAfterReconnect(t *testing.T) {
+	s, _ := newTestServer(t)
+	g := s.manager.CreateGame()
+	player := g.State.AddPlayer()
+	g.State.Config.DisconnectTimeoutSeconds = 1
+
+	// El cliente nuevo ya está en el hub pero playerConnected todavía no corrió: el temporizador
+	// vence igual y no debe dar por perdida la partida a un jugador conectado
+	s.playerDisconnected(g.ID, player.ID)
+	s.wsHub.Add(&WsClient{gameID: g.ID, playerID: player.ID, done: make(chan struct{})})
+
+	time.Sleep(1200 * time.Millisecond)
+	if g.State.IsGameEndPending() {
+		t.Fatal("a reconnected player must not lose by disconnect timeout")
+	}
+}
+
+func TestStaleDisconnectAfterReconnectKeepsPlayerConnected(t *testing.T) {
+	s, _ := newTestServer(t)
+	g := s.manager.CreateGame()
+	player := g.State.AddPlayer()
+
+	// Reconexión completa y luego llega la baja del socket viejo
+	s.wsHub.Add(&WsClient{gameID: g.ID, playerID: player.ID, done: make(chan struct{})})
+	s.playerConnected(g, player.ID)
+	s.playerDisconnected(g.ID, player.ID)
+
+	if !g.State.IsPlayerConnected(player.ID) {
+		t.Fatal("expected the player to stay connected")
+	}
+	s.disconnects.mu.Lock()
+	pending := len(s.disconnects.timers)
+	s.disconnects.mu.Unlock()
+	if pending != 0 {
+		t.Fatalf("expected no disconnect timer, got %d", pending)
+	}
+}
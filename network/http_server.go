@@ -2,12 +2,13 @@ package network
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"path/filepath"
 	"strconv"
 	"strings"
-	"time"
 
 	"autobattle-server/command"
 	"autobattle-server/game"
@@ -20,6 +21,9 @@ import (
 type HttpServer struct {
 	manager *game.GameManager
 	wsHub   *WsHub
+	decks   game.DeckStore
+
+	disconnects *disconnectTimers
 }
 
 const playgameDistPath = "frontend/dist"
@@ -30,25 +34,35 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-func NewHttpServer(manager *game.GameManager, hub *WsHub) *HttpServer {
+func NewHttpServer(manager *game.GameManager, hub *WsHub, decks game.DeckStore) *HttpServer {
 	return &HttpServer{
 		manager: manager,
 		wsHub:   hub,
+		decks:   decks,
+
+		disconnects: newDisconnectTimers(),
 	}
 }
 
 // enableCORS adds CORS headers to allow requests from browsers
 func enableCORS(w http.ResponseWriter) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 }
 
 func (s *HttpServer) Start() {
+	http.ListenAndServe(":7070", s.routes())
+}
+
+// routes arma el enrutador de la API (separado de Start para poder montarlo en pruebas)
+func (s *HttpServer) routes() http.Handler {
+	mux := http.NewServeMux()
+
 	// Static frontend compiled by Vite, served under /playgame
 	playgameFS := http.FileServer(http.Dir(playgameDistPath))
-	http.Handle("/playgame/", http.StripPrefix("/playgame/", playgameFS))
-	http.HandleFunc("/playgame", func(w http.ResponseWriter, r *http.Request) {
+	mux.Handle("/playgame/", http.StripPrefix("/playgame/", playgameFS))
+	mux.HandleFunc("/playgame", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/playgame" {
 			http.NotFound(w, r)
 			return
@@ -56,18 +70,23 @@ func (s *HttpServer) Start() {
 		http.ServeFile(w, r, filepath.Join(playgameDistPath, "index.html"))
 	})
 
-	http.HandleFunc("/game/create", s.handleCreateGame)
-	http.HandleFunc("/game/join", s.handleJoin)
-	http.HandleFunc("/game/state", s.handleGameState)
-	http.HandleFunc("/command/send", s.handleSendCommand)
-	http.HandleFunc("/unit-stats", s.handleUnitStats)
-	http.HandleFunc("/ws", s.handleWebSocket)
-	http.HandleFunc("/openapi.yml", s.handleOpenAPI)
-	http.HandleFunc("/docs", s.handleSwaggerUI)
-	http.HandleFunc("/api/docs", s.handleDocIndex)
-	http.HandleFunc("/api/readme", s.handleReadme)
-
-	http.ListenAndServe(":7070", nil)
+	mux.HandleFunc("/game/create", s.handleCreateGame)
+	mux.HandleFunc("/game/join", s.handleJoin)
+	mux.HandleFunc("/game/presets", s.handlePresets)
+	mux.HandleFunc("/game/state", s.handleGameState)
+	mux.HandleFunc("/game/stats", s.handleGameStats)
+	mux.HandleFunc("/command/send", s.handleSendCommand)
+	mux.HandleFunc("/unit-stats", s.handleUnitStats)
+	mux.HandleFunc("/spells", s.handleSpells)
+	mux.HandleFunc("/decks", s.handleDecks)
+	mux.HandleFunc("/decks/rules", s.handleDeckRules)
+	mux.HandleFunc("/ws", s.handleWebSocket)
+	mux.HandleFunc("/openapi.yml", s.handleOpenAPI)
+	mux.HandleFunc("/docs", s.handleSwaggerUI)
+	mux.HandleFunc("/api/docs", s.handleDocIndex)
+	mux.HandleFunc("/api/readme", s.handleReadme)
+
+	return mux
 }
 
 func (s *HttpServer) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
@@ -154,27 +173,25 @@ func (s *HttpServer) handleCreateGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Intentar leer configuración del body (opcional)
+	// Body opcional: preset base y configuración parcial que se fusiona encima
 	var requestBody struct {
-		Config *game.PhaseConfig `json:"config"`
+		Preset string          `json:"preset"`
+		Config json.RawMessage `json:"config"`
 	}
-
-	var createdGame *game.Game
-
-	// Si hay body, intentar parsearlo
-	if r.ContentLength > 0 {
-		if err := json.NewDecoder(r.Body).Decode(&requestBody); err == nil && requestBody.Config != nil {
-			// Crear juego con configuración personalizada
-			createdGame = s.manager.CreateGameWithConfig(*requestBody.Config)
-		} else {
-			// Si hay error o no hay config, usar valores por defecto
-			createdGame = s.manager.CreateGame()
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil && !errors.Is(err, io.EOF) {
+			writeConfigError(w, &game.ConfigValidationError{Fields: []game.ConfigFieldError{{Field: "body", Message: err.Error()}}})
+			return
 		}
-	} else {
-		// Sin body, usar configuración por defecto
-		createdGame = s.manager.CreateGame()
 	}
 
+	config, err := game.BuildConfig(requestBody.Preset, requestBody.Config)
+	if err != nil {
+		writeConfigError(w, err)
+		return
+	}
+	createdGame := s.manager.CreateGameWithConfig(config)
+
 	snapshot := createdGame.State.GetSnapshot()
 
 	response := map[string]interface{}{
@@ -209,6 +226,39 @@ func (s *HttpServer) handleGameState(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(snapshot)
 }
 
+// handleGameStats retorna las estadísticas de la partida: en curso (acumuladas hasta ahora) o
+// terminada (junto con el resultado)
+func (s *HttpServer) handleGameStats(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	gameID, err := strconv.Atoi(r.URL.Query().Get("gameId"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	response := map[string]any{"gameId": gameID}
+	if g, ok := s.manager.GetGame(gameID); ok {
+		response["finished"] = false
+		response["stats"] = g.State.GetMatchStats()
+	} else if end, ok := s.manager.GetMatchResult(gameID); ok {
+		response["finished"] = true
+		result := *end
+		result.Stats = nil
+		response["stats"] = end.Stats
+		response["result"] = result
+	} else {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 func (s *HttpServer) handleJoin(w http.ResponseWriter, r *http.Request) {
 	enableCORS(w)
 	if r.Method == http.MethodOptions {
@@ -227,13 +277,49 @@ func (s *HttpServer) handleJoin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Mazo elegido (opcional): clave de dueño + deckId de un mazo guardado
+	var deck []string
+	if deckIDStr := r.URL.Query().Get("deckId"); deckIDStr != "" {
+		deckID, err := strconv.Atoi(deckIDStr)
+		if err != nil {
+			http.Error(w, "invalid deckId", http.StatusBadRequest)
+			return
+		}
+		owner := r.URL.Query().Get("owner")
+		if !game.IsDeckOwnerKey(owner) {
+			http.Error(w, errInvalidDeckOwner, http.StatusBadRequest)
+			return
+		}
+		saved, err := s.decks.GetDeck(owner, deckID)
+		if errors.Is(err, game.ErrDeckNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			slog.Error("Failed to load deck", "deckId", deckID, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// Revalidar: las reglas pueden haber cambiado desde que se guardó
+		if err := game.DefaultDeckRules().Validate(saved.Cards); err != nil {
+			writeDeckError(w, err)
+			return
+		}
+		deck = saved.Cards
+	}
+
 	game, ok := s.manager.GetGame(gameID)
 	if !ok {
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
 
-	player := game.State.AddPlayer()
+	player := game.State.AddPlayerWithDeck(deck)
+	if player == nil {
+		// Partida por equipos sin asientos humanos libres
+		http.Error(w, "no free seats", http.StatusConflict)
+		return
+	}
 	json.NewEncoder(w).Encode(player)
 }
 
@@ -253,52 +339,52 @@ func (s *HttpServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Opcional: reanudar sesión; el cliente indica el último seq de evento que recibió
+	lastSeq := -1
+	if sStr := r.URL.Query().Get("lastSeq"); sStr != "" {
+		if seq, convErr := strconv.Atoi(sStr); convErr == nil && seq >= 0 {
+			lastSeq = seq
+		}
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
 	}
 
-	client := &WsClient{
-		conn:     conn,
-		gameID:   gameID,
-		playerID: playerID,
+	g, ok := s.manager.GetGame(gameID)
+	if !ok {
+		// La partida ya terminó: informar el resultado (si se conserva) y cerrar
+		if result, found := s.manager.GetMatchResult(gameID); found {
+			_ = conn.WriteJSON(game.BuildGameEndedMessage(gameID, result))
+		}
+		conn.Close()
+		return
 	}
 
-	s.wsHub.Add(client)
+	client := s.wsHub.newClient(conn, gameID, playerID)
+	if lastSeq >= 0 {
+		// Reenviar estado actual y eventos perdidos antes de cualquier broadcast: el alta se hace
+		// con el lock del estado tomado. El cliente descarta los seq ya recibidos.
+		g.State.ResumeSession(playerID, lastSeq, func(resume game.ResumeMessage) {
+			s.wsHub.AddWithFirst(client, resume)
+		})
+	} else {
+		s.wsHub.Add(client)
+	}
 
-	// Mark player connected if identified
+	// Mark player connected if identified (cancela el temporizador de desconexión)
 	if playerID > 0 {
-		if g, ok := s.manager.GetGame(gameID); ok {
-			g.State.SetPlayerConnected(playerID, true)
-			slog.Info("Player connected", "gameId", gameID, "playerId", playerID)
-		}
+		s.playerConnected(g, playerID)
 	}
 
-	// lectura pasiva (mantiene viva la conexión)
+	// lectura pasiva con heartbeat: termina al cerrarse el socket o sin pong dentro de pongWait
 	go func() {
-		defer s.wsHub.Remove(client)
-		for {
-			if _, _, err := conn.ReadMessage(); err != nil {
-				// On disconnect mark player as disconnected and start timeout
-				if client.playerID > 0 {
-					if g, ok := s.manager.GetGame(client.gameID); ok {
-						g.State.SetPlayerConnected(client.playerID, false)
-						slog.Info("Player disconnected", "gameId", client.gameID, "playerId", client.playerID)
-
-						// Start timeout from config to end game if still offline
-						go func(gid, pid int, timeoutSeconds int) {
-							time.Sleep(time.Duration(timeoutSeconds) * time.Second)
-							if g2, ok2 := s.manager.GetGame(gid); ok2 {
-								if !g2.State.IsPlayerConnected(pid) {
-									slog.Info("Disconnect timeout reached; ending game", "gameId", gid, "playerId", pid, "timeoutSeconds", timeoutSeconds)
-									s.manager.EndGame(gid, pid, "disconnect_timeout")
-								}
-							}
-						}(client.gameID, client.playerID, g.State.Config.DisconnectTimeoutSeconds)
-					}
-				}
-				return
-			}
+		client.readLoop()
+		s.wsHub.Remove(client)
+		// On disconnect mark player as disconnected and start timeout
+		if client.playerID > 0 {
+			s.playerDisconnected(client.gameID, client.playerID)
 		}
 	}()
 }
@@ -321,6 +407,9 @@ func (s *HttpServer) handleUnitStats(w http.ResponseWriter, r *http.Request) {
 		game.TypeWall,
 		game.TypeLandGenerator,
 		game.TypeNavalGenerator,
+		game.TypeSiegeRam,
+		game.TypeMedic,
+		game.TypeEngineer,
 	}
 
 	stats := make(map[string]game.UnitStats)
@@ -332,6 +421,173 @@ func (s *HttpServer) handleUnitStats(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(stats)
 }
 
+func (s *HttpServer) handleSpells(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Retornar reglas de todas las cartas de hechizo
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(game.SpellDefs())
+}
+
+// errInvalidDeckOwner es la respuesta cuando owner no es una clave emitida por POST /decks
+const errInvalidDeckOwner = "owner must be the deck owner key returned by POST /decks"
+
+// handleDecks lista (GET), guarda (POST) o borra (DELETE) los mazos de un jugador. Los mazos
+// se agrupan por una clave secreta que emite el primer POST sin owner: no hay cuentas de
+// jugador, así que quien tenga la clave tiene acceso a sus mazos.
+func (s *HttpServer) handleDecks(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	switch r.Method {
+	case http.MethodOptions:
+		w.WriteHeader(http.StatusOK)
+
+	case http.MethodGet:
+		owner := r.URL.Query().Get("owner")
+		if !game.IsDeckOwnerKey(owner) {
+			http.Error(w, errInvalidDeckOwner, http.StatusBadRequest)
+			return
+		}
+		decks, err := s.decks.ListDecks(owner)
+		if err != nil {
+			slog.Error("Failed to list decks", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(decks)
+
+	case http.MethodPost:
+		var payload struct {
+			Owner string   `json:"owner"`
+			Name  string   `json:"name"`
+			Cards []string `json:"cards"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, "invalid body", http.StatusBadRequest)
+			return
+		}
+		// Sin owner es el primer mazo del jugador: se le emite una clave nueva
+		if payload.Owner == "" {
+			owner, err := game.NewDeckOwnerKey()
+			if err != nil {
+				slog.Error("Failed to create deck owner key", "error", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			payload.Owner = owner
+		} else if !game.IsDeckOwnerKey(payload.Owner) {
+			http.Error(w, errInvalidDeckOwner, http.StatusBadRequest)
+			return
+		}
+		if payload.Name == "" || len(payload.Name) > 64 {
+			http.Error(w, "name must have 1-64 characters", http.StatusBadRequest)
+			return
+		}
+		if err := game.DefaultDeckRules().Validate(payload.Cards); err != nil {
+			writeDeckError(w, err)
+			return
+		}
+		deck, err := s.decks.SaveDeck(payload.Owner, payload.Name, payload.Cards)
+		if err != nil {
+			slog.Error("Failed to save deck", "name", payload.Name, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(deck)
+
+	case http.MethodDelete:
+		deckID, err := strconv.Atoi(r.URL.Query().Get("deckId"))
+		if err != nil {
+			http.Error(w, "invalid deckId", http.StatusBadRequest)
+			return
+		}
+		owner := r.URL.Query().Get("owner")
+		if !game.IsDeckOwnerKey(owner) {
+			http.Error(w, errInvalidDeckOwner, http.StatusBadRequest)
+			return
+		}
+		err = s.decks.DeleteDeck(owner, deckID)
+		if errors.Is(err, game.ErrDeckNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			slog.Error("Failed to delete deck", "deckId", deckID, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// handleDeckRules retorna las reglas de armado de mazos (tamaño, copias, cartas permitidas)
+func (s *HttpServer) handleDeckRules(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(game.DefaultDeckRules())
+}
+
+// handlePresets lista los presets de configuración disponibles para /game/create
+func (s *HttpServer) handlePresets(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(game.ConfigPresets())
+}
+
+// writeConfigError responde 400 con la lista de campos inválidos de una configuración
+func writeConfigError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	var invalid *game.ConfigValidationError
+	if errors.As(err, &invalid) {
+		json.NewEncoder(w).Encode(invalid)
+		return
+	}
+	json.NewEncoder(w).Encode(game.ConfigValidationError{Fields: []game.ConfigFieldError{{Field: "config", Message: err.Error()}}})
+}
+
+// writeDeckError responde 400 con la lista de problemas de un mazo inválido
+func writeDeckError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	var invalid *game.DeckValidationError
+	if errors.As(err, &invalid) {
+		json.NewEncoder(w).Encode(invalid)
+		return
+	}
+	json.NewEncoder(w).Encode(game.DeckValidationError{Problems: []string{err.Error()}})
+}
+
 // handleDocIndex sirve una página de índice de documentación
 func (s *HttpServer) handleDocIndex(w http.ResponseWriter, r *http.Request) {
 	enableCORS(w)
@@ -0,0 +1,169 @@
+package network
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"autobattle-server/game"
+)
+
+func TestMain(m *testing.M) {
+	// Los handlers registran con slog; en las pruebas solo interesa la respuesta
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// newTestServer monta la API completa sobre un servidor HTTP de prueba
+func newTestServer(t *testing.T) (*HttpServer, *httptest.Server) {
+	t.Helper()
+
+	s := NewHttpServer(game.NewGameManager(), NewWsHub(), game.NewMemoryDeckStore())
+	ts := httptest.NewServer(s.routes())
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+// validDeck arma un mazo que cumple las reglas por defecto
+func validDeck() []string {
+	cards := make([]string, 0, 40)
+	for len(cards) < 40 {
+		cards = append(cards, game.TypeTower, game.TypeWall)
+	}
+	return cards
+}
+
+func postJSON(t *testing.T, url string, body any) *http.Response {
+	t.Helper()
+
+	raw, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("marshal body: %v", err)
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestSaveDeckIssuesOwnerKey(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	resp := postJSON(t, ts.URL+"/decks", map[string]any{"name": "towers", "cards": validDeck()})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var deck game.Deck
+	if err := json.NewDecoder(resp.Body).Decode(&deck); err != nil {
+		t.Fatalf("decode deck: %v", err)
+	}
+	if !game.IsDeckOwnerKey(deck.Owner) {
+		t.Fatalf("expected an issued owner key, got %q", deck.Owner)
+	}
+
+	list, err := http.Get(ts.URL + "/decks?owner=" + deck.Owner)
+	if err != nil {
+		t.Fatalf("GET /decks: %v", err)
+	}
+	defer list.Body.Close()
+	var decks []game.Deck
+	if err := json.NewDecoder(list.Body).Decode(&decks); err != nil {
+		t.Fatalf("decode decks: %v", err)
+	}
+	if len(decks) != 1 || decks[0].ID != deck.ID {
+		t.Errorf("expected the saved deck with its key, got %+v", decks)
+	}
+}
+
+func TestDecksRejectChosenOwnerNames(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	resp := postJSON(t, ts.URL+"/decks", map[string]any{"owner": "alice", "name": "towers", "cards": validDeck()})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("POST with a chosen owner name: expected 400, got %d", resp.StatusCode)
+	}
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/decks?owner=alice"},
+		{http.MethodDelete, "/decks?owner=alice&deckId=1"},
+		{http.MethodPost, "/game/join?gameId=1&owner=alice&deckId=1"},
+	} {
+		r, _ := http.NewRequest(req.method, ts.URL+req.path, nil)
+		resp, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatalf("%s %s: %v", req.method, req.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s %s: expected 400, got %d", req.method, req.path, resp.StatusCode)
+		}
+	}
+}
+
+func TestDecksAreScopedToTheirOwnerKey(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	var deck game.Deck
+	resp := postJSON(t, ts.URL+"/decks", map[string]any{"name": "towers", "cards": validDeck()})
+	if err := json.NewDecoder(resp.Body).Decode(&deck); err != nil {
+		t.Fatalf("decode deck: %v", err)
+	}
+	other, err := game.NewDeckOwnerKey()
+	if err != nil {
+		t.Fatalf("new owner key: %v", err)
+	}
+
+	list, err := http.Get(ts.URL + "/decks?owner=" + other)
+	if err != nil {
+		t.Fatalf("GET /decks: %v", err)
+	}
+	defer list.Body.Close()
+	if body, _ := io.ReadAll(list.Body); strings.TrimSpace(string(body)) != "[]" {
+		t.Errorf("expected no decks for another key, got %s", body)
+	}
+
+	r, _ := http.NewRequest(http.MethodDelete, ts.URL+"/decks?owner="+other+"&deckId="+strconv.Itoa(deck.ID), nil)
+	resp, err = http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatalf("DELETE /decks: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("deleting with another key: expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestCreateGameRejectsInvalidConfig(t *testing.T) {
+	s, ts := newTestServer(t)
+
+	resp := postJSON(t, ts.URL+"/game/create", map[string]any{
+		"preset": game.PresetBlitz,
+		"config": map[string]any{"battleDuration": 0, "cardsPerTurn": -1},
+	})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+	var invalid game.ConfigValidationError
+	if err := json.NewDecoder(resp.Body).Decode(&invalid); err != nil {
+		t.Fatalf("decode error body: %v", err)
+	}
+	fields := map[string]bool{}
+	for _, f := range invalid.Fields {
+		fields[f.Field] = true
+	}
+	if len(invalid.Fields) != 2 || !fields["battleDuration"] || !fields["cardsPerTurn"] {
+		t.Errorf("expected battleDuration and cardsPerTurn to be reported, got %+v", invalid.Fields)
+	}
+	if games := s.manager.GetAllGames(); len(games) != 0 {
+		t.Errorf("expected no game to be created, got %d", len(games))
+	}
+}
@@ -1,25 +1,186 @@
 package network
 
 import (
+	"encoding/json"
+	"log/slog"
+	"slices"
 	"sync"
+	"time"
+
+	"autobattle-server/game"
 
 	"github.com/gorilla/websocket"
 )
 
+const (
+	// Tiempo máximo para escribir un mensaje; un cliente que no lo acepta se considera muerto
+	writeWait = 10 * time.Second
+	// Tiempo máximo sin recibir nada (ni pong) antes de cerrar la conexión
+	pongWait = 60 * time.Second
+	// Intervalo de pings; menor que pongWait para que el pong llegue a tiempo
+	pingPeriod = pongWait * 9 / 10
+	// Tamaño máximo de un mensaje del cliente (solo se esperan pongs/cierres)
+	maxMessageSize = 4096
+	// Mensajes encolados por cliente (~25 segundos de snapshots + eventos a 5 ticks/s)
+	sendQueueSize = 256
+)
+
+// outbound es un mensaje ya serializado listo para escribir al cliente
+type outbound struct {
+	data []byte
+	// droppable indica que un mensaje posterior lo reemplaza (snapshots); si la cola se llena
+	// se descarta antes de desconectar al cliente
+	droppable bool
+}
+
 type WsClient struct {
 	conn     *websocket.Conn
 	gameID   int
 	playerID int
+
+	// Límites copiados del hub al crear el cliente
+	pongWait   time.Duration
+	pingPeriod time.Duration
+	queueSize  int
+
+	// queue guarda los mensajes pendientes en orden; wake avisa al escritor que hay nuevos
+	mu        sync.Mutex
+	queue     []outbound
+	wake      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newClient crea un cliente con los límites del hub y arranca su goroutine de escritura (único
+// escritor del socket). El alta en el hub se hace aparte con Add o AddWithFirst.
+func (h *WsHub) newClient(conn *websocket.Conn, gameID, playerID int) *WsClient {
+	c := &WsClient{
+		conn:       conn,
+		gameID:     gameID,
+		playerID:   playerID,
+		pongWait:   h.pongWait,
+		pingPeriod: h.pingPeriod,
+		queueSize:  h.queueSize,
+		wake:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	go c.writeLoop()
+	return c
+}
+
+// close detiene el escritor, que cierra el socket; el lector falla y da de baja al cliente
+func (c *WsClient) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// enqueue encola un mensaje sin bloquear. Con la cola llena descarta el snapshot más viejo
+// pendiente (uno posterior lo reemplaza); si no queda ninguno retorna false: el cliente no da
+// abasto con los mensajes que no se pueden perder y hay que desconectarlo.
+func (c *WsClient) enqueue(msg outbound) bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.queue) >= c.queueSize {
+		i := slices.IndexFunc(c.queue, func(m outbound) bool { return m.droppable })
+		if i < 0 {
+			return false
+		}
+		c.queue = slices.Delete(c.queue, i, i+1)
+	}
+	c.queue = append(c.queue, msg)
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+		// El escritor ya tiene un aviso pendiente
+	}
+	return true
+}
+
+// next saca el mensaje más viejo de la cola
+func (c *WsClient) next() (outbound, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.queue) == 0 {
+		return outbound{}, false
+	}
+	msg := c.queue[0]
+	c.queue[0] = outbound{}
+	c.queue = c.queue[1:]
+	return msg, true
+}
+
+// writeLoop escribe los mensajes encolados y envía pings periódicos, con deadline en cada
+// escritura para no quedar colgado en un cliente muerto
+func (c *WsClient) writeLoop() {
+	ticker := time.NewTicker(c.pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-c.wake:
+			// Se saca de a uno: mientras se escribe, los snapshots encolados siguen descartables
+			for msg, ok := c.next(); ok; msg, ok = c.next() {
+				c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+				if err := c.conn.WriteMessage(websocket.TextMessage, msg.data); err != nil {
+					slog.Info("WebSocket write failed", "gameId", c.gameID, "playerId", c.playerID, "error", err)
+					return
+				}
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				slog.Info("WebSocket ping failed", "gameId", c.gameID, "playerId", c.playerID, "error", err)
+				return
+			}
+		case <-c.done:
+			_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(writeWait))
+			return
+		}
+	}
+}
+
+// readLoop consume los mensajes del cliente (mantiene vivos los pongs) hasta que la conexión
+// falla o pasa pongWait sin recibir nada
+func (c *WsClient) readLoop() {
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(c.pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(c.pongWait))
+	})
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(c.pongWait))
+	}
 }
 
 type WsHub struct {
 	mu      sync.Mutex
 	clients map[*WsClient]struct{}
+
+	// Límites de los clientes nuevos; las pruebas los acortan
+	pongWait   time.Duration
+	pingPeriod time.Duration
+	queueSize  int
 }
 
 func NewWsHub() *WsHub {
 	return &WsHub{
-		clients: make(map[*WsClient]struct{}),
+		clients:    make(map[*WsClient]struct{}),
+		pongWait:   pongWait,
+		pingPeriod: pingPeriod,
+		queueSize:  sendQueueSize,
 	}
 }
 
@@ -29,20 +190,81 @@ func (h *WsHub) Add(client *WsClient) {
 	h.clients[client] = struct{}{}
 }
 
+// AddWithFirst encola first como primer mensaje del cliente y recién entonces lo da de alta,
+// así ningún broadcast le llega antes (p.ej. la reanudación de sesión)
+func (h *WsHub) AddWithFirst(client *WsClient, first any) {
+	data, err := json.Marshal(first)
+	if err != nil {
+		slog.Error("WebSocket payload encoding failed", "error", err)
+	} else {
+		client.enqueue(outbound{data: data, droppable: isDroppable(first)})
+	}
+	h.Add(client)
+}
+
 func (h *WsHub) Remove(client *WsClient) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	delete(h.clients, client)
-	client.conn.Close()
+	client.close()
 }
 
+// Broadcast encola un mensaje para todos los clientes del juego sin bloquear el tick
 func (h *WsHub) Broadcast(gameID int, payload any) {
+	h.deliver(payload, func(c *WsClient) bool { return c.gameID == gameID })
+}
+
+// HasPlayer indica si queda algún cliente conectado identificado con playerID en el juego
+func (h *WsHub) HasPlayer(gameID, playerID int) bool {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	for c := range h.clients {
-		if c.gameID == gameID {
-			_ = c.conn.WriteJSON(payload)
+		if c.gameID == gameID && c.playerID == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// SendToPlayer envía un mensaje solo a los clientes identificados con playerID en el juego
+func (h *WsHub) SendToPlayer(gameID, playerID int, payload any) {
+	h.deliver(payload, func(c *WsClient) bool { return c.gameID == gameID && c.playerID == playerID })
+}
+
+// deliver serializa el mensaje una sola vez y lo encola en los clientes elegidos. El lock solo
+// cubre la selección: la escritura la hace el writeLoop de cada cliente. Los clientes cuya cola
+// está llena de mensajes no descartables se desconectan (pueden reanudar con lastSeq).
+func (h *WsHub) deliver(payload any, match func(c *WsClient) bool) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("WebSocket payload encoding failed", "error", err)
+		return
+	}
+	msg := outbound{data: data, droppable: isDroppable(payload)}
+
+	h.mu.Lock()
+	targets := make([]*WsClient, 0, len(h.clients))
+	for c := range h.clients {
+		if match(c) {
+			targets = append(targets, c)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, c := range targets {
+		if !c.enqueue(msg) {
+			slog.Warn("WebSocket client too slow; disconnecting", "gameId", c.gameID, "playerId", c.playerID)
+			h.Remove(c)
 		}
 	}
 }
+
+// isDroppable indica si el mensaje queda obsoleto con el siguiente (estado completo del juego)
+func isDroppable(payload any) bool {
+	switch payload.(type) {
+	case game.UpdateMessage, *game.UpdateMessage:
+		return true
+	}
+	return false
+}
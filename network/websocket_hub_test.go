@@ -0,0 +1,244 @@
+package network
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"autobattle-server/game"
+
+	"github.com/gorilla/websocket"
+)
+
+// dialGame abre un WebSocket de prueba contra /ws con los parámetros indicados
+func dialGame(t *testing.T, ts *httptest.Server, query string) *websocket.Conn {
+	t.Helper()
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws?" + query
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial %s: %v", url, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readType lee el próximo mensaje del socket y retorna su campo type
+func readType(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var msg struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("decode %s: %v", data, err)
+	}
+	return msg.Type
+}
+
+func TestResumeIsSentBeforeBroadcasts(t *testing.T) {
+	s, ts := newTestServer(t)
+	g := s.manager.CreateGame()
+	player := g.State.AddPlayer()
+
+	// El loop de ticks difunde sin parar mientras el cliente reconecta
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				s.wsHub.Broadcast(g.ID, map[string]string{"type": "events"})
+			}
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		conn := dialGame(t, ts, "gameId="+strconv.Itoa(g.ID)+"&playerId="+strconv.Itoa(player.ID)+"&lastSeq=0")
+		if msgType := readType(t, conn); msgType != "resume" {
+			t.Fatalf("attempt %d: expected resume as the first message, got %q", i, msgType)
+		}
+		conn.Close()
+	}
+}
+
+// eventually espera hasta que cond se cumpla o falla la prueba
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// joinAndDial crea una partida con un jugador y lo conecta por WebSocket sin reanudar sesión
+func joinAndDial(t *testing.T, s *HttpServer, ts *httptest.Server) (*websocket.Conn, int, int) {
+	t.Helper()
+
+	g := s.manager.CreateGame()
+	player := g.State.AddPlayer()
+	conn := dialGame(t, ts, "gameId="+strconv.Itoa(g.ID)+"&playerId="+strconv.Itoa(player.ID))
+	eventually(t, "client registration", func() bool { return s.wsHub.HasPlayer(g.ID, player.ID) })
+	return conn, g.ID, player.ID
+}
+
+// bigSnapshot arma un UpdateMessage pesado para llenar los buffers del socket
+func bigSnapshot(tick int) game.UpdateMessage {
+	dead := make([]int, 50000)
+	for i := range dead {
+		dead[i] = 1000000 + i
+	}
+	return game.UpdateMessage{Type: "update", Tick: tick, Dead: dead}
+}
+
+func TestEnqueueDropsOldestSnapshot(t *testing.T) {
+	c := &WsClient{queueSize: 3, wake: make(chan struct{}, 1), done: make(chan struct{})}
+	event := func(name string) outbound { return outbound{data: []byte(name)} }
+	snapshot := func(name string) outbound { return outbound{data: []byte(name), droppable: true} }
+	queued := func() string {
+		names := make([]string, 0, len(c.queue))
+		for _, m := range c.queue {
+			names = append(names, string(m.data))
+		}
+		return strings.Join(names, ",")
+	}
+
+	for _, m := range []outbound{event("e1"), snapshot("s1"), event("e2"), snapshot("s2"), event("e3")} {
+		if !c.enqueue(m) {
+			t.Fatalf("enqueue %s: expected room after dropping a snapshot", m.data)
+		}
+	}
+	// s1 (en medio de la cola) y luego s2 se descartaron; los eventos siguen en orden
+	if got := queued(); got != "e1,e2,e3" {
+		t.Fatalf("expected e1,e2,e3 queued, got %s", got)
+	}
+
+	if c.enqueue(event("e4")) {
+		t.Fatal("expected a full queue without snapshots to reject the message")
+	}
+	if got := queued(); got != "e1,e2,e3" {
+		t.Fatalf("rejecting must not lose queued messages, got %s", got)
+	}
+}
+
+func TestSlowClientKeepsEventsAndLatestSnapshot(t *testing.T) {
+	s, ts := newTestServer(t)
+	s.wsHub.queueSize = 8
+	conn, gameID, playerID := joinAndDial(t, s, ts)
+
+	// El cliente no lee: el escritor se bloquea en el socket y la cola se llena de snapshots
+	const snapshots = 200
+	events := 0
+	for tick := 0; tick < snapshots; tick++ {
+		s.wsHub.Broadcast(gameID, bigSnapshot(tick))
+		if tick%50 == 0 {
+			s.wsHub.Broadcast(gameID, map[string]int{"seq": events})
+			events++
+		}
+	}
+	if !s.wsHub.HasPlayer(gameID, playerID) {
+		t.Fatal("a client behind only on snapshots must stay connected")
+	}
+
+	var seqs []int
+	updates := 0
+	for {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		var msg struct {
+			Type string `json:"type"`
+			Tick int    `json:"tick"`
+			Seq  *int   `json:"seq"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if msg.Seq != nil {
+			seqs = append(seqs, *msg.Seq)
+			continue
+		}
+		updates++
+		if msg.Tick == snapshots-1 {
+			break
+		}
+	}
+
+	for i, seq := range seqs {
+		if seq != i {
+			t.Fatalf("expected every event in order, got %v", seqs)
+		}
+	}
+	if len(seqs) != events {
+		t.Fatalf("expected %d events, got %v", events, seqs)
+	}
+	if updates >= snapshots {
+		t.Fatalf("expected stale snapshots to be dropped, received all %d", updates)
+	}
+}
+
+func TestSlowClientWithPendingEventsIsDisconnected(t *testing.T) {
+	s, ts := newTestServer(t)
+	s.wsHub.queueSize = 4
+	_, gameID, playerID := joinAndDial(t, s, ts)
+
+	// Mensajes que no se pueden descartar: al llenarse la cola el cliente se da de baja
+	pad := strings.Repeat("x", 256*1024)
+	for i := 0; i < 200 && s.wsHub.HasPlayer(gameID, playerID); i++ {
+		s.wsHub.Broadcast(gameID, map[string]any{"type": "events", "seq": i, "pad": pad})
+	}
+	eventually(t, "slow client removal", func() bool { return !s.wsHub.HasPlayer(gameID, playerID) })
+}
+
+func TestPongsKeepClientConnected(t *testing.T) {
+	s, ts := newTestServer(t)
+	s.wsHub.pongWait = 200 * time.Millisecond
+	s.wsHub.pingPeriod = 50 * time.Millisecond
+	conn, gameID, playerID := joinAndDial(t, s, ts)
+
+	// El cliente de gorilla responde los pings mientras lee
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	time.Sleep(4 * s.wsHub.pongWait)
+	if !s.wsHub.HasPlayer(gameID, playerID) {
+		t.Fatal("a client answering pings must stay connected past pongWait")
+	}
+}
+
+func TestClientWithoutPongsIsDisconnected(t *testing.T) {
+	s, ts := newTestServer(t)
+	s.wsHub.pongWait = 200 * time.Millisecond
+	s.wsHub.pingPeriod = 50 * time.Millisecond
+	_, gameID, playerID := joinAndDial(t, s, ts)
+
+	// Sin leer, el cliente nunca contesta los pings y vence el deadline de lectura
+	start := time.Now()
+	eventually(t, "pong timeout", func() bool { return !s.wsHub.HasPlayer(gameID, playerID) })
+	if elapsed := time.Since(start); elapsed < s.wsHub.pongWait/2 {
+		t.Fatalf("client dropped after %v, before pongWait", elapsed)
+	}
+}